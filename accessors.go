@@ -0,0 +1,272 @@
+package id3v2
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// textFrame looks up and decodes a text information frame, returning
+// ErrFrameNotFound if the tag doesn't have one.
+func textFrame(tag Tag, id string) (string, error) {
+	data, ok := tag.GetFrame(id)
+	if !ok {
+		return "", ErrFrameNotFound
+	}
+	return DecodeTextFrame(data)
+}
+
+// firstTextFrame decodes the first text information frame the tag has out of
+// ids, like the ID3v2.3.0 and ID3v2.2.0 IDs of the same frame, and returns the
+// ID it was found by. It returns ErrFrameNotFound if the tag has none of them.
+func firstTextFrame(tag Tag, ids ...string) (string, string, error) {
+	for _, id := range ids {
+		if s, err := textFrame(tag, id); err != ErrFrameNotFound {
+			return s, id, err
+		}
+	}
+	return "", "", ErrFrameNotFound
+}
+
+// Title returns the title (TIT2), or ErrFrameNotFound if there isn't one.
+func Title(tag Tag) (string, error) {
+	s, _, err := firstTextFrame(tag, "TIT2", "TT2")
+	return s, err
+}
+
+// Artist returns the lead artist (TPE1), or ErrFrameNotFound if there isn't
+// one.
+func Artist(tag Tag) (string, error) {
+	s, _, err := firstTextFrame(tag, "TPE1", "TP1")
+	return s, err
+}
+
+// Album returns the album (TALB), or ErrFrameNotFound if there isn't one.
+func Album(tag Tag) (string, error) {
+	s, _, err := firstTextFrame(tag, "TALB", "TAL")
+	return s, err
+}
+
+// Year returns the year of the recording, from TYER or the start of the
+// ID3v2.4.0 recording time (TDRC). It returns ErrFrameNotFound if there's
+// neither.
+func Year(tag Tag) (string, error) {
+	s, id, err := firstTextFrame(tag, "TYER", "TDRC", "TYE")
+	if err != nil {
+		return "", err
+	}
+	if id == "TDRC" && len(s) > 4 {
+		s = s[:4]
+	}
+	return s, nil
+}
+
+// Track returns the track number from TRCK, which may be given as "n" or
+// "n/total". It returns ErrFrameNotFound if there isn't one.
+func Track(tag Tag) (int, error) {
+	n, _, err := track(tag)
+	return n, err
+}
+
+// TrackTotal returns the number of tracks from TRCK, or 0 if only the track
+// number is given. It returns ErrFrameNotFound if there isn't one.
+func TrackTotal(tag Tag) (int, error) {
+	_, total, err := track(tag)
+	return total, err
+}
+
+func track(tag Tag) (n, total int, err error) {
+	s, id, err := firstTextFrame(tag, "TRCK", "TRK")
+	if err != nil {
+		return 0, 0, err
+	}
+	return parsePosition(id, s)
+}
+
+// Original describes the original work of a cover, remix or reissue.
+type Original struct {
+	Filename string // TOFN
+	Album    string // TOAL
+	Artist   string // TOPE
+	Lyricist string // TOLY
+	Year     int    // TORY
+}
+
+// OriginalInfo returns the metadata of the original work stored in the TO*
+// frames, or their ID3v2.2.0 equivalents. Fields of missing or undecodable
+// frames are left empty.
+func OriginalInfo(tag Tag) Original {
+	var o Original
+
+	o.Filename, _, _ = firstTextFrame(tag, "TOFN", "TOF")
+	o.Album, _, _ = firstTextFrame(tag, "TOAL", "TOT")
+	o.Artist, _, _ = firstTextFrame(tag, "TOPE", "TOA")
+	o.Lyricist, _, _ = firstTextFrame(tag, "TOLY", "TOL")
+
+	if s, _, err := firstTextFrame(tag, "TORY", "TOR"); err == nil {
+		o.Year, _ = strconv.Atoi(s)
+	}
+
+	return o
+}
+
+// TechnicalInfo returns the initial key (TKEY), beats per minute (TBPM) and
+// length (TLEN) of the audio, or their ID3v2.2.0 equivalents. Missing or
+// invalid frames give zero values.
+func TechnicalInfo(tag Tag) (key string, bpm int, length time.Duration) {
+	key, _, _ = firstTextFrame(tag, "TKEY", "TKE")
+
+	if s, _, err := firstTextFrame(tag, "TBPM", "TBP"); err == nil {
+		bpm, _ = strconv.Atoi(s)
+	}
+
+	// The length is given in milliseconds
+	if s, _, err := firstTextFrame(tag, "TLEN", "TLE"); err == nil {
+		if ms, err := strconv.ParseInt(s, 10, 64); err == nil {
+			length = time.Duration(ms) * time.Millisecond
+		}
+	}
+
+	return key, bpm, length
+}
+
+// MainComment returns the text of the main COMM frame of a tag, out of the
+// several many files have. Comments with an empty description are preferred,
+// then the first in the preferred languages (e.g. "eng") in order, then the
+// first comment. It returns false if there are no decodable comments.
+func MainComment(tag Tag, preferredLangs []string) (string, bool) {
+	type comment struct {
+		lang, text string
+	}
+
+	var all, empty []comment
+	for _, data := range tag.FramesByID("COMM") {
+		lang, desc, text, err := parseCOMM(data)
+		if err != nil {
+			continue
+		}
+
+		c := comment{lang, text}
+		all = append(all, c)
+		if desc == "" {
+			empty = append(empty, c)
+		}
+	}
+
+	candidates := empty
+	if len(candidates) == 0 {
+		candidates = all
+	}
+	if len(candidates) == 0 {
+		return "", false
+	}
+
+	for _, lang := range preferredLangs {
+		for _, c := range candidates {
+			if strings.EqualFold(c.lang, lang) {
+				return c.text, true
+			}
+		}
+	}
+
+	return candidates[0].text, true
+}
+
+// MovementName returns the movement name of classical works from the iTunes
+// MVNM frame.
+func MovementName(tag Tag) (string, error) {
+	return textFrame(tag, "MVNM")
+}
+
+// MovementNumber returns the movement number and number of movements from
+// the iTunes MVIN frame, given as "n" or "n/total". The total is 0 if it's
+// not given.
+func MovementNumber(tag Tag) (n, total int, err error) {
+	s, err := textFrame(tag, "MVIN")
+	if err != nil {
+		return 0, 0, err
+	}
+	return parsePosition("MVIN", s)
+}
+
+// parsePosition parses a position given as "n" or "n/total", as used by the
+// TRCK, TPOS and MVIN frames. The total is 0 if it's not given.
+func parsePosition(id, s string) (n, total int, err error) {
+	parts := strings.SplitN(s, "/", 2)
+	if n, err = strconv.Atoi(strings.TrimSpace(parts[0])); err != nil {
+		return 0, 0, fmt.Errorf("id3v2: invalid %s '%s'", id, s)
+	}
+	if len(parts) == 2 {
+		if total, err = strconv.Atoi(strings.TrimSpace(parts[1])); err != nil {
+			return 0, 0, fmt.Errorf("id3v2: invalid %s '%s'", id, s)
+		}
+	}
+
+	return n, total, nil
+}
+
+// Grouping returns the grouping from the iTunes GRP1 frame, or failing that
+// the standard content group (TIT1) most other taggers write. It returns an
+// empty string if there's neither.
+func Grouping(tag Tag) string {
+	s, _, _ := firstTextFrame(tag, "GRP1", "TIT1", "TT1")
+	return s
+}
+
+// SetGrouping writes the grouping to the given frames, by default both GRP1
+// and TIT1 so that it's found whichever of them a player reads. ID3v2.2.0
+// tags default to TT1 only.
+func SetGrouping(tag Tag, grouping string, ids ...string) {
+	if len(ids) == 0 {
+		ids = []string{"GRP1", "TIT1"}
+		if ver, ok := versionOf(tag); ok && ver.major < 3 {
+			ids = []string{"TT1"}
+		}
+	}
+
+	for _, id := range ids {
+		tag.SetFrame(id, EncodeTextFrame(grouping, EncodingAuto))
+	}
+}
+
+// TextSeparator joins the values of text frames holding several, as given by
+// AllText.
+const TextSeparator = "; "
+
+// AllText decodes every text information frame of the tag at once, mapping
+// frame ID to text. The null separated values of ID3v2.4.0 frames and the
+// values of repeated frames are joined by TextSeparator. TXXX frames are keyed
+// "TXXX:" followed by their description. Frames that fail to decode are left
+// out.
+func AllText(tag Tag) map[string]string {
+	m := make(map[string]string)
+
+	tag.EachFrame(func(id string, data []byte) error {
+		if !strings.HasPrefix(id, "T") {
+			return nil
+		}
+
+		var s string
+		var err error
+		if id == "TXXX" || id == "TXX" {
+			var desc string
+			desc, s, err = ParseTXXX(data)
+			id = id + ":" + desc
+		} else {
+			s, err = DecodeTextFrame(data)
+		}
+		if err != nil {
+			return nil
+		}
+
+		s = strings.Replace(s, "\x00", TextSeparator, -1)
+		if prev, ok := m[id]; ok {
+			s = prev + TextSeparator + s
+		}
+		m[id] = s
+		return nil
+	})
+
+	return m
+}
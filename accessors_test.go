@@ -0,0 +1,258 @@
+package id3v2_test
+
+import (
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/jlubawy/go-id3v2"
+	"github.com/jlubawy/go-id3v2/id3v230"
+)
+
+func TestOriginalInfo(t *testing.T) {
+	tag, err := id3v2.NewTag("id3v2.3.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tag.SetFrame("TOAL", []byte("\x00Original Album"))
+	tag.SetFrame("TOPE", []byte("\x00Original Artist"))
+	tag.SetFrame("TORY", []byte("\x001969"))
+
+	expected := id3v2.Original{
+		Album:  "Original Album",
+		Artist: "Original Artist",
+		Year:   1969,
+	}
+	if o := id3v2.OriginalInfo(tag); o != expected {
+		t.Errorf("expected %+v but got %+v", expected, o)
+	}
+}
+
+func TestTechnicalInfo(t *testing.T) {
+	tag, err := id3v2.NewTag("id3v2.3.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tag.SetFrame("TKEY", []byte("\x00Abm"))
+	tag.SetFrame("TLEN", []byte("\x00215000"))
+
+	key, bpm, length := id3v2.TechnicalInfo(tag)
+	if key != "Abm" || bpm != 0 || length != 215*time.Second {
+		t.Errorf("expected Abm, 0, 3m35s but got %s, %d, %s", key, bpm, length)
+	}
+}
+
+func TestMainComment(t *testing.T) {
+	tag := buildTag(t,
+		"COMM", []byte("\x00engiTunNORM\x00 0000"),
+		"COMM", []byte("\x00fra\x00Bonjour"),
+		"COMM", []byte("\x00eng\x00Hello"),
+	)
+
+	tests := []struct {
+		langs    []string
+		expected string
+	}{
+		{nil, "Bonjour"},
+		{[]string{"eng"}, "Hello"},
+		{[]string{"deu", "FRA"}, "Bonjour"},
+	}
+
+	for _, test := range tests {
+		if s, ok := id3v2.MainComment(tag, test.langs); !ok || s != test.expected {
+			t.Errorf("%v: expected '%s' but got '%s'", test.langs, test.expected, s)
+		}
+	}
+
+	tag = buildTag(t, "COMM", []byte("\x00engiTunNORM\x00 0000"))
+	if s, ok := id3v2.MainComment(tag, nil); !ok || s != " 0000" {
+		t.Errorf("expected the only comment ' 0000' but got '%s'", s)
+	}
+
+	tag = buildTag(t, "TIT2", []byte("\x00Test"))
+	if _, ok := id3v2.MainComment(tag, nil); ok {
+		t.Error("expected no comment")
+	}
+}
+
+func TestMovement(t *testing.T) {
+	tag, err := id3v2.NewTag("id3v2.3.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := id3v2.MovementName(tag); err != id3v2.ErrFrameNotFound {
+		t.Errorf("expected ErrFrameNotFound but got %v", err)
+	}
+
+	tag.SetFrame("MVNM", id3v2.EncodeTextFrame("Allegro", id3v2.EncodingAuto))
+	tag.SetFrame("MVIN", id3v2.EncodeTextFrame("2/4", id3v2.EncodingAuto))
+	tag.SetFrame("GRP1", id3v2.EncodeTextFrame("Symphony No. 5", id3v2.EncodingAuto))
+
+	if s, err := id3v2.MovementName(tag); err != nil || s != "Allegro" {
+		t.Errorf("expected 'Allegro' but got '%s' (%v)", s, err)
+	}
+	if n, total, err := id3v2.MovementNumber(tag); err != nil || n != 2 || total != 4 {
+		t.Errorf("expected 2/4 but got %d/%d (%v)", n, total, err)
+	}
+	if s := id3v2.Grouping(tag); s != "Symphony No. 5" {
+		t.Errorf("expected 'Symphony No. 5' but got '%s'", s)
+	}
+
+	if err := id3v230.Encode(ioutil.Discard, tag); err != nil {
+		t.Errorf("expected the movement frames to encode but got %v", err)
+	}
+
+	tag.SetFrame("MVIN", id3v2.EncodeTextFrame("II", id3v2.EncodingAuto))
+	if _, _, err := id3v2.MovementNumber(tag); err == nil {
+		t.Error("expected an error for a non-numeric MVIN")
+	}
+}
+
+func TestAccessorsV220(t *testing.T) {
+	tag, err := id3v2.NewTag("id3v2.2.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tag.SetFrame("TOT", []byte("\x00Original Album"))
+	tag.SetFrame("TOA", []byte("\x00Original Artist"))
+	tag.SetFrame("TOR", []byte("\x001969"))
+	tag.SetFrame("TKE", []byte("\x00Abm"))
+	tag.SetFrame("TBP", []byte("\x00120"))
+	tag.SetFrame("TLE", []byte("\x00215000"))
+	tag.SetFrame("WAR", []byte("http://example.com/artist"))
+	tag.SetFrame("WPB", []byte("http://example.com/publisher"))
+
+	expected := id3v2.Original{
+		Album:  "Original Album",
+		Artist: "Original Artist",
+		Year:   1969,
+	}
+	if o := id3v2.OriginalInfo(tag); o != expected {
+		t.Errorf("expected %+v but got %+v", expected, o)
+	}
+
+	key, bpm, length := id3v2.TechnicalInfo(tag)
+	if key != "Abm" || bpm != 120 || length != 215*time.Second {
+		t.Errorf("expected Abm, 120, 3m35s but got %s, %d, %s", key, bpm, length)
+	}
+
+	for _, test := range []struct {
+		fn       func(id3v2.Tag) (string, error)
+		expected string
+	}{
+		{id3v2.ArtistURL, "http://example.com/artist"},
+		{id3v2.PublisherURL, "http://example.com/publisher"},
+	} {
+		if s, err := test.fn(tag); err != nil || s != test.expected {
+			t.Errorf("expected '%s' but got '%s' (%v)", test.expected, s, err)
+		}
+	}
+}
+
+func TestCommonAccessors(t *testing.T) {
+	tag := buildTag(t,
+		"TIT2", []byte("\x00Test"),
+		"TPE1", []byte("\x01\xFF\xFEA\x00r\x00t\x00"),
+		"TYER", []byte("\x002016"),
+		"TRCK", []byte("\x003/12"),
+	)
+
+	for _, test := range []struct {
+		fn       func(id3v2.Tag) (string, error)
+		expected string
+	}{
+		{id3v2.Title, "Test"},
+		{id3v2.Artist, "Art"},
+		{id3v2.Year, "2016"},
+	} {
+		if s, err := test.fn(tag); err != nil || s != test.expected {
+			t.Errorf("expected '%s' but got '%s' (%v)", test.expected, s, err)
+		}
+	}
+
+	if n, err := id3v2.Track(tag); err != nil || n != 3 {
+		t.Errorf("expected track 3 but got %d (%v)", n, err)
+	}
+	if n, err := id3v2.TrackTotal(tag); err != nil || n != 12 {
+		t.Errorf("expected 12 tracks but got %d (%v)", n, err)
+	}
+
+	if s, err := id3v2.Album(tag); s != "" || err != id3v2.ErrFrameNotFound {
+		t.Errorf("expected '' and ErrFrameNotFound for a missing album but got '%s', %v", s, err)
+	}
+
+	tag = buildTag(t, "TDRC", []byte("\x002016-04-01"), "TRCK", []byte("\x00A"))
+	if s, err := id3v2.Year(tag); err != nil || s != "2016" {
+		t.Errorf("expected year 2016 from TDRC but got '%s' (%v)", s, err)
+	}
+	if _, err := id3v2.Track(tag); err == nil {
+		t.Error("expected an error for an invalid TRCK")
+	}
+}
+
+func TestAllText(t *testing.T) {
+	tag := buildTag(t,
+		"TIT2", []byte("\x00Test"),
+		"TPE1", []byte("\x03One\x00Two\x00"),
+		"TPE1", []byte("\x00Three"),
+		"TXXX", []byte("\x00MusicBrainz Album Id\x00abc"),
+		"COMM", []byte("\x00eng\x00Not text"),
+		"TALB", []byte("\x04Bad"),
+	)
+
+	expected := map[string]string{
+		"TIT2":                      "Test",
+		"TPE1":                      "One; Two; Three",
+		"TXXX:MusicBrainz Album Id": "abc",
+	}
+
+	m := id3v2.AllText(tag)
+	if len(m) != len(expected) {
+		t.Errorf("expected %v but got %v", expected, m)
+	}
+	for id, s := range expected {
+		if m[id] != s {
+			t.Errorf("%s: expected '%s' but got '%s'", id, s, m[id])
+		}
+	}
+}
+
+func TestGrouping(t *testing.T) {
+	tag, err := id3v2.NewTag("id3v2.3.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s := id3v2.Grouping(tag); s != "" {
+		t.Errorf("expected no grouping but got '%s'", s)
+	}
+
+	tag.SetFrame("TIT1", id3v2.EncodeTextFrame("Content Group", id3v2.EncodingAuto))
+	if s := id3v2.Grouping(tag); s != "Content Group" {
+		t.Errorf("expected 'Content Group' from TIT1 but got '%s'", s)
+	}
+
+	tag.SetFrame("GRP1", id3v2.EncodeTextFrame("iTunes Group", id3v2.EncodingAuto))
+	if s := id3v2.Grouping(tag); s != "iTunes Group" {
+		t.Errorf("expected GRP1 to be preferred but got '%s'", s)
+	}
+
+	id3v2.SetGrouping(tag, "Both")
+	for _, id := range []string{"GRP1", "TIT1"} {
+		if s, _ := id3v2.DecodeTextFrame(tag.Frames()[id]); s != "Both" {
+			t.Errorf("expected %s 'Both' but got '%s'", id, s)
+		}
+	}
+
+	id3v2.SetGrouping(tag, "Standard", "TIT1")
+	if s, _ := id3v2.DecodeTextFrame(tag.Frames()["TIT1"]); s != "Standard" {
+		t.Errorf("expected TIT1 'Standard' but got '%s'", s)
+	}
+	if s := id3v2.Grouping(tag); s != "Both" {
+		t.Errorf("expected GRP1 to be left alone but got '%s'", s)
+	}
+}
@@ -0,0 +1,63 @@
+package id3v2
+
+import (
+	"fmt"
+)
+
+// Frames introduced by ID3v2.4.0, which EncodeAs drops when writing
+// ID3v2.3.0 unless it converts them. TSOP isn't included since iTunes wrote
+// it in ID3v2.3.0 tags long before, and the id3v230 package supports it.
+var frames240Only = map[string]bool{
+	"ASPI": true, "EQU2": true, "RVA2": true, "SEEK": true, "SIGN": true,
+	"TDEN": true, "TDOR": true, "TDRC": true, "TDRL": true, "TDTG": true,
+	"TIPL": true, "TMCL": true, "TMOO": true, "TPRO": true, "TSOA": true,
+	"TSOT": true, "TSST": true,
+}
+
+// Frames of ID3v2.3.0 removed by ID3v2.4.0.
+var frames230Only = map[string]bool{
+	"EQUA": true, "IPLS": true, "RVAD": true, "TDAT": true, "TIME": true,
+	"TORY": true, "TRDA": true, "TSIZ": true, "TYER": true,
+}
+
+// A sizeReporter is a tag that can describe the sizes it was decoded with
+// which weren't encoded as its version requires, such as frame sizes read as
+// synchsafe in an ID3v2.3.0 tag.
+type sizeReporter interface {
+	SizeAnomalies() []string
+}
+
+// DetectVersionAnomalies returns a description of each frame in the tag that
+// doesn't belong to the declared version (e.g. "id3v2.3.0"), such as a TDRC
+// frame in a tag claiming to be ID3v2.3.0, which suggests the file is
+// mislabeled. Sizes the tag was decoded with in the encoding of another
+// version are described too, such as synchsafe frame sizes in an ID3v2.3.0
+// tag decoded with the options to repair them.
+func DetectVersionAnomalies(tag Tag, declared string) []string {
+	var anomalies []string
+
+	seen := make(map[string]bool)
+	for _, id := range tag.FrameOrder() {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		switch {
+		case declared == "id3v2.2.0" && len(id) != 3:
+			anomalies = append(anomalies, fmt.Sprintf("frame %s has a %d character ID but ID3v2.2.0 uses 3 characters", id, len(id)))
+		case declared != "id3v2.2.0" && len(id) != 4:
+			anomalies = append(anomalies, fmt.Sprintf("frame %s has a %d character ID but %s uses 4 characters", id, len(id), declared))
+		case declared == "id3v2.3.0" && frames240Only[id]:
+			anomalies = append(anomalies, fmt.Sprintf("frame %s is only defined by ID3v2.4.0", id))
+		case declared == "id3v2.4.0" && frames230Only[id]:
+			anomalies = append(anomalies, fmt.Sprintf("frame %s was removed in ID3v2.4.0", id))
+		}
+	}
+
+	if sr, ok := tag.(sizeReporter); ok {
+		anomalies = append(anomalies, sr.SizeAnomalies()...)
+	}
+
+	return anomalies
+}
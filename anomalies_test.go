@@ -0,0 +1,66 @@
+package id3v2_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/jlubawy/go-id3v2"
+	"github.com/jlubawy/go-id3v2/id3v230"
+)
+
+func TestDetectVersionAnomalies(t *testing.T) {
+	tag, err := id3v2.NewTag("id3v2.3.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tag.SetFrame("TIT2", []byte("\x00Test"))
+	tag.SetFrame("TDRC", []byte("\x002017"))
+	tag.SetFrame("TYER", []byte("\x002017"))
+	tag.SetFrame("TSOP", []byte("\x00Band, The"))
+
+	if a := id3v2.DetectVersionAnomalies(tag, "id3v2.3.0"); len(a) != 1 {
+		t.Errorf("expected 1 anomaly for TDRC in ID3v2.3.0 but got %v", a)
+	}
+	if a := id3v2.DetectVersionAnomalies(tag, "id3v2.4.0"); len(a) != 1 {
+		t.Errorf("expected 1 anomaly for TYER in ID3v2.4.0 but got %v", a)
+	}
+}
+
+func TestDetectVersionAnomaliesSizes(t *testing.T) {
+	// A TIT2 frame of 200 bytes whose size is synchsafe, as in ID3v2.4.0
+	synchSafe := []byte{
+		'I', 'D', '3', 3, 0, 0, 0, 0, 1, 82,
+		'T', 'I', 'T', '2', 0, 0, 1, 72, 0, 0,
+	}
+	synchSafe = append(synchSafe, bytes.Repeat([]byte{0x01}, 200)...)
+
+	// A TIT2 frame of 150 bytes with the tag size 160 written as a plain
+	// integer
+	plain := []byte{
+		'I', 'D', '3', 3, 0, 0, 0, 0, 0, 0xA0,
+		'T', 'I', 'T', '2', 0, 0, 0, 150, 0, 0,
+	}
+	plain = append(plain, bytes.Repeat([]byte{0x01}, 150)...)
+
+	cases := []struct {
+		b        []byte
+		expected string
+	}{
+		{synchSafe, "frame TIT2 has a synchsafe size as in ID3v2.4.0"},
+		{plain, "tag size is a plain integer rather than synchsafe"},
+	}
+
+	opts := id3v230.DecodeOptions{RepairTagSize: true, RepairFrameSizes: true}
+	for _, c := range cases {
+		tag, err := id3v230.DecodeWithOptions(bytes.NewReader(c.b), opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if a := id3v2.DetectVersionAnomalies(tag, "id3v2.3.0"); !reflect.DeepEqual(a, []string{c.expected}) {
+			t.Errorf("expected anomaly %q but got %q", c.expected, a)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package id3v2
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// The MIME type of an APIC frame which links to its picture rather than
+// embedding it.
+const APICLinkMIMEType = "-->"
+
+// The furthest into an APIC frame the description terminator is looked for,
+// enough for 64 UTF-16 characters and their BOM with room to spare.
+const maxAPICDescriptionSize = 256
+
+// APIC is an attached picture frame.
+//
+// Text encoding   $xx
+// MIME type       <text string> $00
+// Picture type    $xx
+// Description     <text string according to encoding> $00 (00)
+// Picture data    <binary data>
+type APIC struct {
+	Encoding    Encoding
+	MIMEType    string
+	PictureType byte
+	Description string
+	Data        []byte
+
+	// IsLink is true if the MIME type is "-->", in which case the picture
+	// data is the URL of the picture, also given by URL.
+	IsLink bool
+	URL    string
+}
+
+// ParseAPIC parses the data of an APIC frame.
+func ParseAPIC(data []byte) (APIC, error) {
+	var pic APIC
+
+	if len(data) < 1 {
+		return pic, errShortFrame("APIC")
+	}
+	pic.Encoding = Encoding(data[0])
+
+	i := bytes.IndexByte(data[1:], 0)
+	if i < 0 || 1+i+1 >= len(data) {
+		return pic, errShortFrame("APIC")
+	}
+	pic.MIMEType = string(data[1 : 1+i])
+	pic.PictureType = data[1+i+1]
+
+	// Some tools leave out the description terminator and start the picture
+	// data straight after the picture type. Image data is recognised by its
+	// signature since it usually holds nulls of its own, otherwise the
+	// description is at most 64 characters, so a terminator much further in
+	// is part of the picture.
+	desc, rest, ok := splitString(byte(pic.Encoding), data[1+i+2:])
+	if isImageData(data[1+i+2:]) || !ok || len(desc) > maxAPICDescriptionSize {
+		desc, rest = nil, data[1+i+2:]
+	}
+
+	var err error
+	if pic.Description, err = decodeString(byte(pic.Encoding), desc); err != nil {
+		return pic, err
+	}
+	pic.Data = rest
+
+	if pic.MIMEType == APICLinkMIMEType {
+		pic.IsLink = true
+		pic.URL = trimNull(string(pic.Data))
+	}
+
+	return pic, nil
+}
+
+// isImageData returns true if b starts with the signature of a PNG, JPEG, GIF
+// or BMP image. A BMP is only recognised by its reserved bytes being zero too,
+// since "BM" could also start a description.
+func isImageData(b []byte) bool {
+	switch {
+	case bytes.HasPrefix(b, []byte("\x89PNG\r\n\x1a\n")):
+		return true
+	case bytes.HasPrefix(b, []byte{0xFF, 0xD8, 0xFF}):
+		return true
+	case bytes.HasPrefix(b, []byte("GIF87a")) || bytes.HasPrefix(b, []byte("GIF89a")):
+		return true
+	case len(b) >= 10 && bytes.HasPrefix(b, []byte("BM")) && bytes.Equal(b[6:10], []byte{0, 0, 0, 0}):
+		return true
+	}
+	return false
+}
+
+// The highest picture type defined by the specification, $14 for a publisher
+// or studio logotype.
+const maxAPICPictureType = 0x14
+
+// EncodeAPIC encodes a picture as the data of an APIC frame, the description
+// ending with a null terminator as wide as the encoding calls for. An
+// encoding of EncodingAuto picks one able to represent the description. The
+// URL of a link is written as its picture data if Data is empty.
+func EncodeAPIC(pic APIC) ([]byte, error) {
+	if pic.MIMEType == "" {
+		return nil, fmt.Errorf("id3v2: APIC MIME type must not be empty")
+	}
+	if !isISO88591(pic.MIMEType) {
+		return nil, fmt.Errorf("id3v2: APIC MIME type must be ISO-8859-1 but got '%s'", pic.MIMEType)
+	}
+	if pic.PictureType > maxAPICPictureType {
+		return nil, fmt.Errorf("id3v2: APIC picture type must be at most $%02X but got $%02X", maxAPICPictureType, pic.PictureType)
+	}
+	if pic.Encoding > EncodingUTF8 && pic.Encoding != EncodingAuto {
+		return nil, fmt.Errorf("id3v2: APIC has unknown text encoding $%02X", byte(pic.Encoding))
+	}
+
+	// The encoded description starts with the encoding byte EncodingAuto
+	// resolves to
+	desc := EncodeTextFrame(pic.Description, pic.Encoding)
+
+	b := []byte{desc[0]}
+	for _, r := range pic.MIMEType {
+		b = append(b, byte(r))
+	}
+	b = append(b, 0, pic.PictureType)
+	b = append(b, desc[1:]...)
+
+	if len(pic.Data) == 0 && pic.MIMEType == APICLinkMIMEType {
+		return append(b, pic.URL...), nil
+	}
+	return append(b, pic.Data...), nil
+}
@@ -0,0 +1,77 @@
+package id3v2
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestParseAPIC(t *testing.T) {
+	pic, err := ParseAPIC([]byte("\x00image/png\x00\x03Cover\x00\x89PNG"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pic.MIMEType != "image/png" || pic.PictureType != 0x03 || pic.Description != "Cover" || !bytes.Equal(pic.Data, []byte("\x89PNG")) {
+		t.Errorf("unexpected picture %+v", pic)
+	}
+	if pic.IsLink {
+		t.Error("expected an embedded picture not to be a link")
+	}
+
+	pic, err = ParseAPIC([]byte("\x00-->\x00\x03\x00http://example.com/cover.jpg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pic.IsLink || pic.URL != "http://example.com/cover.jpg" {
+		t.Errorf("expected a link to http://example.com/cover.jpg but got %+v", pic)
+	}
+}
+
+func TestParseAPICMissingDescriptionTerminator(t *testing.T) {
+	png := append([]byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1A, '\n'}, bytes.Repeat([]byte{0xAB}, 300)...)
+	png = append(png, 0)
+
+	// No terminator at all
+	pic, err := ParseAPIC(append([]byte("\x00image/png\x00\x03"), png[:len(png)-1]...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pic.Description != "" || !bytes.Equal(pic.Data, png[:len(png)-1]) {
+		t.Errorf("expected an empty description and all the picture data but got '%s' and %d bytes", pic.Description, len(pic.Data))
+	}
+
+	// A null far into the picture data isn't the terminator
+	if pic, err = ParseAPIC(append([]byte("\x00image/png\x00\x03"), png...)); err != nil {
+		t.Fatal(err)
+	}
+	if pic.Description != "" || !bytes.Equal(pic.Data, png) {
+		t.Errorf("expected an empty description and all the picture data but got '%s' and %d bytes", pic.Description, len(pic.Data))
+	}
+}
+
+func TestParseAPICMissingDescriptionImageData(t *testing.T) {
+	images := map[string][]byte{
+		"PNG":  []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\x0dIHDR\x00\x00\x00\x01"),
+		"JPEG": []byte("\xff\xd8\xff\xe0\x00\x10JFIF\x00\x01\x01"),
+		"GIF":  []byte("GIF89a\x01\x00\x01\x00\x00\x00\x00"),
+		"BMP":  []byte("BM\x3a\x00\x00\x00\x00\x00\x00\x00\x36\x00"),
+	}
+
+	for name, img := range images {
+		pic, err := ParseAPIC(append([]byte("\x00image/x\x00\x03"), img...))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pic.Description != "" || !bytes.Equal(pic.Data, img) {
+			t.Errorf("%s: expected an empty description and all the picture data but got %q and %q", name, pic.Description, pic.Data)
+		}
+	}
+
+	// A description is still split off before the image
+	pic, err := ParseAPIC(append([]byte("\x00image/png\x00\x03Cover\x00"), images["PNG"]...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pic.Description != "Cover" || !bytes.Equal(pic.Data, images["PNG"]) {
+		t.Errorf("expected description 'Cover' and the PNG but got %q and %q", pic.Description, pic.Data)
+	}
+}
@@ -0,0 +1,39 @@
+package id3v2
+
+import (
+	"io"
+)
+
+// VerifyAudioFollows returns true if the next bytes of r begin with a valid
+// MPEG audio frame header, such as straight after decoding a tag. A false
+// result suggests the declared tag size is wrong and what follows is leftover
+// tag data rather than audio. Four bytes are read from r.
+//
+// AAAAAAAA AAABBCCD EEEEFFGH (frame sync A, version B, layer C, bitrate E,
+// sampling rate F)
+func VerifyAudioFollows(r io.Reader) (bool, error) {
+	var h [4]byte
+	if _, err := io.ReadFull(r, h[:]); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return isMPEGHeader(h), nil
+}
+
+// isMPEGHeader returns true if h is a valid MPEG audio frame header, with the
+// frame sync set and no reserved version, layer, bitrate or sampling rate.
+func isMPEGHeader(h [4]byte) bool {
+	if h[0] != 0xFF || h[1]&0xE0 != 0xE0 {
+		return false
+	}
+
+	version := (h[1] >> 3) & 0x03
+	layer := (h[1] >> 1) & 0x03
+	bitrate := h[2] >> 4
+	sampling := (h[2] >> 2) & 0x03
+
+	return version != 0x01 && layer != 0x00 && bitrate != 0x0F && sampling != 0x03
+}
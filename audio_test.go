@@ -0,0 +1,73 @@
+package id3v2_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/jlubawy/go-id3v2"
+)
+
+func TestVerifyAudioFollows(t *testing.T) {
+	tests := []struct {
+		b        []byte
+		expected bool
+	}{
+		{[]byte{0xFF, 0xFB, 0x90, 0x64}, true},  // MPEG-1 layer III 128kbps 44.1kHz
+		{[]byte{0xFF, 0xF3, 0x40, 0xC4}, true},  // MPEG-2 layer III
+		{[]byte{0xFF, 0xEB, 0x90, 0x64}, false}, // reserved version
+		{[]byte{0xFF, 0xF9, 0x90, 0x64}, false}, // reserved layer
+		{[]byte{0xFF, 0xFB, 0xF0, 0x64}, false}, // bad bitrate
+		{[]byte{0xFF, 0xFB, 0x9C, 0x64}, false}, // reserved sampling rate
+		{[]byte{0x00, 0x00, 0x00, 0x00}, false}, // padding
+		{[]byte{0xFF, 0xFB}, false},             // too short
+	}
+
+	for _, test := range tests {
+		ok, err := id3v2.VerifyAudioFollows(bytes.NewReader(test.b))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok != test.expected {
+			t.Errorf("%v: expected %t but got %t", test.b, test.expected, ok)
+		}
+	}
+}
+
+func TestCheckTSIZ(t *testing.T) {
+	audio := []byte{0xFF, 0xFB, 0x90, 0x64, 0x00, 0x00, 0x00, 0x00}
+
+	tests := []struct {
+		tsiz     string
+		audio    []byte
+		declared uint64
+		actual   uint64
+	}{
+		{"8", audio, 8, 8},     // matching
+		{"8", audio[:6], 8, 6}, // truncated
+		{"6", audio, 6, 8},     // padded
+		{"0", []byte{}, 0, 0},  // no audio
+		{"12", audio, 12, 8},   // mismatching
+	}
+
+	for _, test := range tests {
+		tag := buildTag(t, "TSIZ", []byte("\x00"+test.tsiz))
+
+		declared, actual, err := id3v2.CheckTSIZ(tag, bytes.NewReader(test.audio))
+		if err != nil {
+			t.Fatalf("TSIZ %s: %v", test.tsiz, err)
+		}
+		if declared != test.declared || actual != test.actual {
+			t.Errorf("TSIZ %s: expected %d declared and %d actual bytes but got %d and %d", test.tsiz, test.declared, test.actual, declared, actual)
+		}
+	}
+
+	tag := buildTag(t, "TIT2", []byte("\x00Test"))
+	if _, _, err := id3v2.CheckTSIZ(tag, bytes.NewReader(audio)); err != id3v2.ErrFrameNotFound {
+		t.Errorf("expected ErrFrameNotFound without a TSIZ frame but got %v", err)
+	}
+
+	tag = buildTag(t, "TSIZ", []byte("\x00not a size"))
+	if _, _, err := id3v2.CheckTSIZ(tag, bytes.NewReader(audio)); err == nil {
+		t.Error("expected an error for a TSIZ frame that isn't a number")
+	}
+}
@@ -0,0 +1,167 @@
+package id3v2
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+)
+
+// Chapter is a CHAP frame from the ID3v2 chapter frame addendum.
+//
+// Element ID      <text string> $00
+// Start time      $xx xx xx xx
+// End time        $xx xx xx xx
+// Start offset    $xx xx xx xx
+// End offset      $xx xx xx xx
+// <Optional embedded sub-frames>
+type Chapter struct {
+	ElementID   string
+	StartTime   time.Duration
+	EndTime     time.Duration
+	StartOffset uint32
+	EndOffset   uint32
+	SubFrames   []byte
+}
+
+// TableOfContents is a CTOC frame from the ID3v2 chapter frame addendum.
+//
+// Element ID      <text string> $00
+// CTOC flags      %000000ab
+// Entry count     $xx
+// Child element ID  <text string> $00 /* zero or more child element IDs */
+// <Optional embedded sub-frames>
+type TableOfContents struct {
+	ElementID       string
+	TopLevel        bool
+	Ordered         bool
+	ChildElementIDs []string
+	SubFrames       []byte
+}
+
+// CTOC flags
+const (
+	ctocFlagOrdered  = byte(1 << 0)
+	ctocFlagTopLevel = byte(1 << 1)
+)
+
+// ParseCHAP parses the data of a CHAP frame.
+func ParseCHAP(data []byte) (Chapter, error) {
+	var c Chapter
+
+	id, rest, ok := splitString(encodingISO88591, data)
+	if !ok || len(rest) < 16 {
+		return c, errShortFrame("CHAP")
+	}
+
+	c.ElementID = string(id)
+	c.StartTime = time.Duration(binary.BigEndian.Uint32(rest[0:4])) * time.Millisecond
+	c.EndTime = time.Duration(binary.BigEndian.Uint32(rest[4:8])) * time.Millisecond
+	c.StartOffset = binary.BigEndian.Uint32(rest[8:12])
+	c.EndOffset = binary.BigEndian.Uint32(rest[12:16])
+	c.SubFrames = rest[16:]
+
+	return c, nil
+}
+
+// ParseCTOC parses the data of a CTOC frame.
+func ParseCTOC(data []byte) (TableOfContents, error) {
+	var toc TableOfContents
+
+	id, rest, ok := splitString(encodingISO88591, data)
+	if !ok || len(rest) < 2 {
+		return toc, errShortFrame("CTOC")
+	}
+
+	toc.ElementID = string(id)
+	toc.TopLevel = rest[0]&ctocFlagTopLevel != 0
+	toc.Ordered = rest[0]&ctocFlagOrdered != 0
+
+	count := int(rest[1])
+	rest = rest[2:]
+	for i := 0; i < count; i++ {
+		var child []byte
+		if child, rest, ok = splitString(encodingISO88591, rest); !ok {
+			return toc, fmt.Errorf("id3v2: expected %d CTOC child element IDs but got %d", count, i)
+		}
+		toc.ChildElementIDs = append(toc.ChildElementIDs, string(child))
+	}
+	toc.SubFrames = rest
+
+	return toc, nil
+}
+
+// A ChapterNode is an element of a chapter tree. Exactly one of Chapter and
+// TableOfContents is set, only tables of contents have children.
+type ChapterNode struct {
+	Chapter         *Chapter
+	TableOfContents *TableOfContents
+	Children        []*ChapterNode
+}
+
+// BuildChapterTree resolves the CTOC and CHAP frames of a tag into a tree
+// rooted at the top-level table of contents, following nested tables of
+// contents to any depth. It returns an error if a child element doesn't exist
+// or the references form a cycle.
+func BuildChapterTree(tag Tag) (*ChapterNode, error) {
+	chapters := make(map[string]*Chapter)
+	tocs := make(map[string]*TableOfContents)
+	var top *TableOfContents
+
+	for _, data := range tag.FramesByID("CHAP") {
+		c, err := ParseCHAP(data)
+		if err != nil {
+			return nil, err
+		}
+		chapters[c.ElementID] = &c
+	}
+	for _, data := range tag.FramesByID("CTOC") {
+		toc, err := ParseCTOC(data)
+		if err != nil {
+			return nil, err
+		}
+		tocs[toc.ElementID] = &toc
+
+		if toc.TopLevel {
+			if top != nil {
+				return nil, fmt.Errorf("id3v2: expected one top-level CTOC but got '%s' and '%s'", top.ElementID, toc.ElementID)
+			}
+			top = &toc
+		}
+	}
+
+	if top == nil {
+		return nil, fmt.Errorf("id3v2: no top-level CTOC frame")
+	}
+
+	var build func(toc *TableOfContents, path []string) (*ChapterNode, error)
+	build = func(toc *TableOfContents, path []string) (*ChapterNode, error) {
+		for _, id := range path {
+			if id == toc.ElementID {
+				return nil, fmt.Errorf("id3v2: CTOC reference cycle through '%s'", toc.ElementID)
+			}
+		}
+		path = append(path, toc.ElementID)
+
+		node := &ChapterNode{TableOfContents: toc}
+		for _, id := range toc.ChildElementIDs {
+			if c, ok := chapters[id]; ok {
+				node.Children = append(node.Children, &ChapterNode{Chapter: c})
+				continue
+			}
+
+			child, ok := tocs[id]
+			if !ok {
+				return nil, fmt.Errorf("id3v2: CTOC '%s' references unknown element '%s'", toc.ElementID, id)
+			}
+
+			n, err := build(child, path)
+			if err != nil {
+				return nil, err
+			}
+			node.Children = append(node.Children, n)
+		}
+		return node, nil
+	}
+
+	return build(top, nil)
+}
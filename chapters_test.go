@@ -0,0 +1,135 @@
+package id3v2_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/jlubawy/go-id3v2"
+)
+
+// buildTag builds an ID3v2.3 tag from a list of frame IDs and data.
+func buildTag(t *testing.T, frames ...interface{}) id3v2.Tag {
+	body := &bytes.Buffer{}
+	for i := 0; i < len(frames); i += 2 {
+		data := frames[i+1].([]byte)
+		body.WriteString(frames[i].(string))
+		binary.Write(body, binary.BigEndian, uint32(len(data)))
+		body.Write([]byte{0, 0})
+		body.Write(data)
+	}
+
+	b := &bytes.Buffer{}
+	b.Write([]byte{'I', 'D', '3', 3, 0, 0})
+	binary.Write(b, binary.BigEndian, id3v2.SizeToSynchSafe(uint32(body.Len())))
+	b.Write(body.Bytes())
+
+	tag, _, err := id3v2.Decode(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tag
+}
+
+func chapFrame(id string, start, end uint32) []byte {
+	b := &bytes.Buffer{}
+	b.WriteString(id)
+	b.WriteByte(0)
+	binary.Write(b, binary.BigEndian, []uint32{start, end, 0xFFFFFFFF, 0xFFFFFFFF})
+	return b.Bytes()
+}
+
+func ctocFrame(id string, flags byte, children ...string) []byte {
+	b := &bytes.Buffer{}
+	b.WriteString(id)
+	b.Write([]byte{0, flags, byte(len(children))})
+	for _, c := range children {
+		b.WriteString(c)
+		b.WriteByte(0)
+	}
+	return b.Bytes()
+}
+
+func TestParseCTOC(t *testing.T) {
+	toc, err := id3v2.ParseCTOC(ctocFrame("toc", 0x03, "ch1", "ch2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if toc.ElementID != "toc" || !toc.TopLevel || !toc.Ordered {
+		t.Errorf("expected top-level ordered 'toc' but got %+v", toc)
+	}
+	if len(toc.ChildElementIDs) != 2 || toc.ChildElementIDs[0] != "ch1" || toc.ChildElementIDs[1] != "ch2" {
+		t.Errorf("expected children [ch1 ch2] but got %v", toc.ChildElementIDs)
+	}
+
+	if _, err := id3v2.ParseCTOC(ctocFrame("toc", 0x03, "ch1")[:7]); err == nil {
+		t.Error("expected error for missing child element ID")
+	}
+}
+
+func TestParseCHAP(t *testing.T) {
+	c, err := id3v2.ParseCHAP(chapFrame("ch1", 1000, 2500))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.ElementID != "ch1" || c.StartTime != time.Second || c.EndTime != 2500*time.Millisecond {
+		t.Errorf("expected ch1 from 1s to 2.5s but got %+v", c)
+	}
+}
+
+func TestBuildChapterTree(t *testing.T) {
+	tag := buildTag(t,
+		"CTOC", ctocFrame("toc", 0x03, "ch1", "sub"),
+		"CTOC", ctocFrame("sub", 0x01, "ch2", "ch3"),
+		"CHAP", chapFrame("ch1", 0, 1000),
+		"CHAP", chapFrame("ch2", 1000, 2000),
+		"CHAP", chapFrame("ch3", 2000, 3000),
+	)
+
+	root, err := id3v2.BuildChapterTree(tag)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if root.TableOfContents == nil || root.TableOfContents.ElementID != "toc" || len(root.Children) != 2 {
+		t.Fatalf("expected root 'toc' with 2 children but got %+v", root)
+	}
+	if c := root.Children[0].Chapter; c == nil || c.ElementID != "ch1" {
+		t.Errorf("expected first child 'ch1' but got %+v", root.Children[0])
+	}
+	sub := root.Children[1]
+	if sub.TableOfContents == nil || sub.TableOfContents.ElementID != "sub" || len(sub.Children) != 2 {
+		t.Fatalf("expected nested 'sub' with 2 children but got %+v", sub)
+	}
+	if c := sub.Children[1].Chapter; c == nil || c.ElementID != "ch3" {
+		t.Errorf("expected last child 'ch3' but got %+v", sub.Children[1])
+	}
+}
+
+func TestBuildChapterTreeErrors(t *testing.T) {
+	tests := map[string]id3v2.Tag{
+		"cycle": buildTag(t,
+			"CTOC", ctocFrame("toc", 0x03, "a"),
+			"CTOC", ctocFrame("a", 0x01, "b"),
+			"CTOC", ctocFrame("b", 0x01, "a"),
+		),
+		"self": buildTag(t,
+			"CTOC", ctocFrame("toc", 0x03, "toc"),
+		),
+		"unknown": buildTag(t,
+			"CTOC", ctocFrame("toc", 0x03, "missing"),
+		),
+		"no top-level": buildTag(t,
+			"CHAP", chapFrame("ch1", 0, 1000),
+		),
+	}
+
+	for name, tag := range tests {
+		if _, err := id3v2.BuildChapterTree(tag); err == nil {
+			t.Errorf("%s: expected error but got nil", name)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package id3v2
+
+// Comment is a comment frame.
+//
+// Text encoding           $xx
+// Language                $xx xx xx
+// Short content descrip.  <text string according to encoding> $00 (00)
+// The actual text         <full text string according to encoding>
+type Comment struct {
+	Encoding    Encoding
+	Language    string
+	Description string
+	Text        string
+}
+
+// ParseCOMM parses the data of a COMM frame.
+func ParseCOMM(data []byte) (Comment, error) {
+	lang, desc, text, err := parseCOMM(data)
+	if err != nil {
+		return Comment{}, err
+	}
+
+	return Comment{
+		Encoding:    Encoding(data[0]),
+		Language:    lang,
+		Description: desc,
+		Text:        text,
+	}, nil
+}
+
+// Lyrics is an unsynchronised lyrics frame, which shares the layout of COMM.
+//
+// Text encoding        $xx
+// Language             $xx xx xx
+// Content descriptor   <text string according to encoding> $00 (00)
+// Lyrics/text          <full text string according to encoding>
+type Lyrics struct {
+	Encoding    Encoding
+	Language    string
+	Description string
+	Text        string
+}
+
+// ParseUSLT parses the data of a USLT frame. Lines of the lyrics are kept
+// as they are, separated by newlines.
+func ParseUSLT(data []byte) (Lyrics, error) {
+	if len(data) < 4 {
+		return Lyrics{}, errShortFrame("USLT")
+	}
+
+	lang, desc, text, err := parseCOMM(data)
+	if err != nil {
+		return Lyrics{}, err
+	}
+
+	return Lyrics{
+		Encoding:    Encoding(data[0]),
+		Language:    lang,
+		Description: desc,
+		Text:        text,
+	}, nil
+}
+
+// EncodeCOMM encodes a comment as the data of a COMM frame. An encoding of
+// EncodingAuto picks one able to represent both the description and text.
+func EncodeCOMM(c Comment) ([]byte, error) {
+	return encodeCOMM("COMM", c.Encoding, c.Language, c.Description, c.Text)
+}
+
+// EncodeUSLT encodes lyrics as the data of a USLT frame. An encoding of
+// EncodingAuto picks one able to represent both the description and text.
+func EncodeUSLT(l Lyrics) ([]byte, error) {
+	return encodeCOMM("USLT", l.Encoding, l.Language, l.Description, l.Text)
+}
+
+// encodeCOMM encodes the data of a COMM frame or a frame sharing its layout,
+// named id in errors.
+func encodeCOMM(id string, enc Encoding, lang, desc, text string) ([]byte, error) {
+	if err := checkLanguage(id, lang); err != nil {
+		return nil, err
+	}
+
+	enc = resolveEncoding(enc, desc, text)
+	if err := checkEncoding(id, enc); err != nil {
+		return nil, err
+	}
+
+	b := []byte{byte(enc)}
+	b = append(b, lang...)
+	b = append(b, encodeString(enc, desc)...)
+	b = append(b, terminator(enc)...)
+	b = append(b, encodeString(enc, text)...)
+	return b, nil
+}
+
+// TermsOfUse is a terms of use frame (USER).
+//
+// Text encoding   $xx
+// Language        $xx xx xx
+// The actual text <text string according to encoding>
+type TermsOfUse struct {
+	Encoding Encoding
+	Language string
+	Text     string
+}
+
+// ParseUSER parses the data of a USER frame.
+func ParseUSER(data []byte) (TermsOfUse, error) {
+	if len(data) < 1+LanguageLength {
+		return TermsOfUse{}, errShortFrame("USER")
+	}
+
+	text, err := decodeString(data[0], data[1+LanguageLength:])
+	if err != nil {
+		return TermsOfUse{}, err
+	}
+
+	return TermsOfUse{
+		Encoding: Encoding(data[0]),
+		Language: string(data[1 : 1+LanguageLength]),
+		Text:     trimNull(text),
+	}, nil
+}
+
+// EncodeUSER encodes terms of use as the data of a USER frame. An encoding of
+// EncodingAuto picks one able to represent the text.
+func EncodeUSER(u TermsOfUse) ([]byte, error) {
+	if err := checkLanguage("USER", u.Language); err != nil {
+		return nil, err
+	}
+
+	enc := resolveEncoding(u.Encoding, u.Text)
+	if err := checkEncoding("USER", enc); err != nil {
+		return nil, err
+	}
+
+	b := []byte{byte(enc)}
+	b = append(b, u.Language...)
+	return append(b, encodeString(enc, u.Text)...), nil
+}
+
+// parseCOMM splits a COMM frame into its language, description and text. USLT
+// frames share the same layout.
+func parseCOMM(data []byte) (lang, desc, text string, err error) {
+	if len(data) < 4 {
+		return "", "", "", errShortFrame("COMM")
+	}
+
+	enc := data[0]
+	lang = string(data[1:4])
+	d, t, _ := splitString(enc, data[4:])
+
+	if desc, err = decodeString(enc, d); err != nil {
+		return "", "", "", err
+	}
+	if text, err = decodeString(enc, t); err != nil {
+		return "", "", "", err
+	}
+
+	return lang, desc, trimNull(text), nil
+}
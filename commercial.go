@@ -0,0 +1,183 @@
+package id3v2
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// Commercial is a commercial frame (COMR), describing how the audio can be
+// bought.
+//
+// Text encoding      $xx
+// Price string       <text string> $00
+// Valid until        <text string>
+// Contact URL        <text string> $00
+// Received as        $xx
+// Name of seller     <text string according to encoding> $00 (00)
+// Description        <text string according to encoding> $00 (00)
+// Picture MIME type  <string> $00
+// Seller logo        <binary data>
+type Commercial struct {
+	Encoding    Encoding
+	Price       string // e.g. "USD9.99", several prices are separated by '/'
+	ValidUntil  string // YYYYMMDD
+	ContactURL  string
+	ReceivedAs  byte
+	Seller      string
+	Description string
+
+	// The seller logo is optional, both are left empty if there isn't one.
+	LogoMIMEType string
+	Logo         []byte
+}
+
+// ParseCOMR parses the data of a COMR frame, including the seller logo that
+// may end it.
+func ParseCOMR(data []byte) (Commercial, error) {
+	var c Commercial
+
+	if len(data) < 1 {
+		return c, errShortFrame("COMR")
+	}
+	c.Encoding = Encoding(data[0])
+	rest := data[1:]
+
+	// The price, date and URL are always ISO-8859-1
+	i := bytes.IndexByte(rest, 0)
+	if i < 0 || len(rest) < i+1+DateLength {
+		return c, errShortFrame("COMR")
+	}
+	c.Price = string(rest[:i])
+	c.ValidUntil = string(rest[i+1 : i+1+DateLength])
+	rest = rest[i+1+DateLength:]
+
+	i = bytes.IndexByte(rest, 0)
+	if i < 0 || i+1 >= len(rest) {
+		return c, errShortFrame("COMR")
+	}
+	c.ContactURL = string(rest[:i])
+	c.ReceivedAs = rest[i+1]
+	rest = rest[i+2:]
+
+	seller, rest, _ := splitString(byte(c.Encoding), rest)
+	desc, rest, _ := splitString(byte(c.Encoding), rest)
+
+	var err error
+	if c.Seller, err = decodeString(byte(c.Encoding), seller); err != nil {
+		return c, err
+	}
+	if c.Description, err = decodeString(byte(c.Encoding), desc); err != nil {
+		return c, err
+	}
+
+	if len(rest) > 0 {
+		mime, logo, _ := splitString(encodingISO88591, rest)
+		c.LogoMIMEType = string(mime)
+		if len(logo) > 0 {
+			c.Logo = logo
+		}
+	}
+
+	return c, nil
+}
+
+// EncodeCOMR encodes a commercial frame as the data of a COMR frame, the
+// seller logo left out if it has no MIME type. An encoding of EncodingAuto
+// picks one able to represent the seller and description. The price, date and
+// contact URL must be ISO-8859-1, and the date YYYYMMDD.
+func EncodeCOMR(c Commercial) ([]byte, error) {
+	for _, f := range []struct{ name, s string }{{"price", c.Price}, {"contact URL", c.ContactURL}, {"logo MIME type", c.LogoMIMEType}} {
+		if !isISO88591(f.s) {
+			return nil, fmt.Errorf("id3v2: COMR %s must be ISO-8859-1 but got '%s'", f.name, f.s)
+		}
+	}
+	if err := checkDate("COMR", "valid until", c.ValidUntil); err != nil {
+		return nil, err
+	}
+
+	enc := resolveEncoding(c.Encoding, c.Seller, c.Description)
+	if err := checkEncoding("COMR", enc); err != nil {
+		return nil, err
+	}
+
+	b := []byte{byte(enc)}
+	b = append(b, encodeString(EncodingISO88591, c.Price)...)
+	b = append(b, 0)
+	b = append(b, c.ValidUntil...)
+	b = append(b, encodeString(EncodingISO88591, c.ContactURL)...)
+	b = append(b, 0, c.ReceivedAs)
+	b = append(b, encodeString(enc, c.Seller)...)
+	b = append(b, terminator(enc)...)
+	b = append(b, encodeString(enc, c.Description)...)
+	b = append(b, terminator(enc)...)
+
+	if c.LogoMIMEType != "" {
+		b = append(b, encodeString(EncodingISO88591, c.LogoMIMEType)...)
+		b = append(b, 0)
+		b = append(b, c.Logo...)
+	}
+	return b, nil
+}
+
+// Ownership is an ownership frame (OWNE), recording the purchase of the audio.
+//
+// Text encoding     $xx
+// Price paid        <text string> $00
+// Date of purch.    <text string>
+// Seller            <text string according to encoding>
+type Ownership struct {
+	Encoding     Encoding
+	PricePaid    string // e.g. "USD9.99"
+	PurchaseDate string // YYYYMMDD
+	Seller       string
+}
+
+// ParseOWNE parses the data of an OWNE frame.
+func ParseOWNE(data []byte) (Ownership, error) {
+	var o Ownership
+
+	if len(data) < 1 {
+		return o, errShortFrame("OWNE")
+	}
+	o.Encoding = Encoding(data[0])
+	rest := data[1:]
+
+	// The price and date are always ISO-8859-1
+	i := bytes.IndexByte(rest, 0)
+	if i < 0 || len(rest) < i+1+DateLength {
+		return o, errShortFrame("OWNE")
+	}
+	o.PricePaid = string(rest[:i])
+	o.PurchaseDate = string(rest[i+1 : i+1+DateLength])
+
+	seller, err := decodeString(byte(o.Encoding), rest[i+1+DateLength:])
+	if err != nil {
+		return o, err
+	}
+	o.Seller = trimNull(seller)
+
+	return o, nil
+}
+
+// EncodeOWNE encodes an ownership frame as the data of an OWNE frame. An
+// encoding of EncodingAuto picks one able to represent the seller. The price
+// must be ISO-8859-1 and the date YYYYMMDD.
+func EncodeOWNE(o Ownership) ([]byte, error) {
+	if !isISO88591(o.PricePaid) {
+		return nil, fmt.Errorf("id3v2: OWNE price paid must be ISO-8859-1 but got '%s'", o.PricePaid)
+	}
+	if err := checkDate("OWNE", "date of purchase", o.PurchaseDate); err != nil {
+		return nil, err
+	}
+
+	enc := resolveEncoding(o.Encoding, o.Seller)
+	if err := checkEncoding("OWNE", enc); err != nil {
+		return nil, err
+	}
+
+	b := []byte{byte(enc)}
+	b = append(b, encodeString(EncodingISO88591, o.PricePaid)...)
+	b = append(b, 0)
+	b = append(b, o.PurchaseDate...)
+	return append(b, encodeString(enc, o.Seller)...), nil
+}
@@ -0,0 +1,94 @@
+package id3v2
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestParseCOMR(t *testing.T) {
+	frame := "\x00USD9.99\x0020261231http://example.com/buy\x00\x02Record Store\x00Digital download\x00"
+
+	c, err := ParseCOMR([]byte(frame + "image/png\x00\x89PNG"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Price != "USD9.99" || c.ValidUntil != "20261231" || c.ContactURL != "http://example.com/buy" || c.ReceivedAs != 0x02 {
+		t.Errorf("unexpected commercial frame %+v", c)
+	}
+	if c.Seller != "Record Store" || c.Description != "Digital download" {
+		t.Errorf("expected seller 'Record Store' and description 'Digital download' but got '%s' and '%s'", c.Seller, c.Description)
+	}
+	if c.LogoMIMEType != "image/png" || !bytes.Equal(c.Logo, []byte("\x89PNG")) {
+		t.Errorf("expected a image/png logo but got '%s' %v", c.LogoMIMEType, c.Logo)
+	}
+
+	c, err = ParseCOMR([]byte(frame))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.LogoMIMEType != "" || c.Logo != nil {
+		t.Errorf("expected no logo but got '%s' %v", c.LogoMIMEType, c.Logo)
+	}
+
+	c, err = ParseCOMR([]byte("\x01USD1\x0020260101\x00\x00\xFF\xFEA\x00\x00\x00\xFF\xFEB\x00\x00\x00"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Seller != "A" || c.Description != "B" {
+		t.Errorf("expected UTF-16 seller 'A' and description 'B' but got '%s' and '%s'", c.Seller, c.Description)
+	}
+
+	if _, err := ParseCOMR([]byte("\x00USD9.99\x002026")); err == nil {
+		t.Error("expected an error for a short frame")
+	}
+}
+
+func TestEncodeCOMR(t *testing.T) {
+	c := Commercial{
+		Encoding:     EncodingAuto,
+		Price:        "USD9.99",
+		ValidUntil:   "20261231",
+		ContactURL:   "http://example.com/buy",
+		ReceivedAs:   0x02,
+		Seller:       "Record Store",
+		Description:  "Digital download",
+		LogoMIMEType: "image/png",
+		Logo:         []byte("\x89PNG"),
+	}
+
+	data, err := EncodeCOMR(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "\x00USD9.99\x0020261231http://example.com/buy\x00\x02Record Store\x00Digital download\x00image/png\x00\x89PNG"; string(data) != expected {
+		t.Errorf("expected %q but got %q", expected, data)
+	}
+
+	c.ValidUntil = "2026-12-31"
+	if _, err := EncodeCOMR(c); err == nil {
+		t.Error("expected an error for a valid until date that isn't YYYYMMDD")
+	}
+}
+
+func TestOWNE(t *testing.T) {
+	o := Ownership{Encoding: EncodingAuto, PricePaid: "USD9.99", PurchaseDate: "20170102", Seller: "Caf\u00e9 Records"}
+
+	data, err := EncodeOWNE(o)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := ParseOWNE(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	o.Encoding = EncodingISO88591
+	if parsed != o {
+		t.Errorf("expected %+v but got %+v", o, parsed)
+	}
+
+	o.PurchaseDate = "2017012"
+	if _, err := EncodeOWNE(o); err == nil {
+		t.Error("expected an error for a date of purchase that isn't YYYYMMDD")
+	}
+}
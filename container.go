@@ -0,0 +1,81 @@
+package id3v2
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"strings"
+)
+
+var ErrNoChunk = errors.New("id3v2: no ID3 chunk found")
+
+// Chunk IDs used to store ID3v2 tags in RIFF and IFF containers.
+const (
+	WAVChunkID  = "id3 "
+	AIFFChunkID = "ID3 "
+)
+
+// Chunk ID   $xx xx xx xx (four characters)
+// Size       $xx xx xx xx
+type chunkHeader struct {
+	ID   [4]byte
+	Size uint32
+}
+
+// DecodeFromWAV walks the chunks of a RIFF WAVE file looking for an ID3 chunk
+// and decodes the tag it contains.
+func DecodeFromWAV(rs io.ReadSeeker) (Tag, string, error) {
+	return decodeFromChunks(rs, binary.LittleEndian, "RIFF", "WAVE")
+}
+
+// DecodeFromAIFF walks the chunks of an AIFF or AIFF-C file looking for an ID3
+// chunk and decodes the tag it contains.
+func DecodeFromAIFF(rs io.ReadSeeker) (Tag, string, error) {
+	return decodeFromChunks(rs, binary.BigEndian, "FORM", "AIFF", "AIFC")
+}
+
+func decodeFromChunks(rs io.ReadSeeker, order binary.ByteOrder, groupID string, formTypes ...string) (Tag, string, error) {
+	var group chunkHeader
+	var formType [4]byte
+
+	if err := binary.Read(rs, order, &group); err != nil {
+		return nil, "", ErrFormat
+	}
+	if _, err := io.ReadFull(rs, formType[:]); err != nil {
+		return nil, "", ErrFormat
+	}
+
+	if string(group.ID[:]) != groupID || !containsString(formTypes, string(formType[:])) {
+		return nil, "", ErrFormat
+	}
+
+	for {
+		var c chunkHeader
+
+		if err := binary.Read(rs, order, &c); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return nil, "", ErrNoChunk
+			}
+			return nil, "", err
+		}
+
+		// Writers disagree on the case of the chunk ID so accept either
+		if strings.EqualFold(string(c.ID[:]), AIFFChunkID) {
+			return Decode(io.LimitReader(rs, int64(c.Size)))
+		}
+
+		// Chunks are padded to an even number of bytes
+		if _, err := rs.Seek(int64(c.Size)+int64(c.Size&1), io.SeekCurrent); err != nil {
+			return nil, "", err
+		}
+	}
+}
+
+func containsString(a []string, s string) bool {
+	for _, v := range a {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package id3v2_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/jlubawy/go-id3v2"
+	_ "github.com/jlubawy/go-id3v2/id3v230"
+)
+
+// testTag is a minimal ID3v2.3.0 tag containing a single TIT2 frame.
+var testTag = []byte{
+	'I', 'D', '3', 3, 0, 0, 0, 0, 0, 15,
+	'T', 'I', 'T', '2', 0, 0, 0, 5, 0, 0,
+	0, 'T', 'e', 's', 't',
+}
+
+func chunk(order binary.ByteOrder, id string, data []byte) []byte {
+	b := &bytes.Buffer{}
+	b.WriteString(id)
+	binary.Write(b, order, uint32(len(data)))
+	b.Write(data)
+	if len(data)%2 != 0 {
+		b.WriteByte(0)
+	}
+	return b.Bytes()
+}
+
+func TestDecodeFromContainers(t *testing.T) {
+	cases := []struct {
+		name   string
+		decode func(r *bytes.Reader) (id3v2.Tag, string, error)
+		order  binary.ByteOrder
+		group  string
+		form   string
+		id     string
+	}{
+		{"WAV", func(r *bytes.Reader) (id3v2.Tag, string, error) { return id3v2.DecodeFromWAV(r) }, binary.LittleEndian, "RIFF", "WAVE", id3v2.WAVChunkID},
+		{"AIFF", func(r *bytes.Reader) (id3v2.Tag, string, error) { return id3v2.DecodeFromAIFF(r) }, binary.BigEndian, "FORM", "AIFF", id3v2.AIFFChunkID},
+	}
+
+	for _, c := range cases {
+		body := &bytes.Buffer{}
+		body.WriteString(c.form)
+		body.Write(chunk(c.order, "junk", []byte{1, 2, 3}))
+		body.Write(chunk(c.order, c.id, testTag))
+
+		file := chunk(c.order, c.group, body.Bytes())
+
+		tag, ver, err := c.decode(bytes.NewReader(file))
+		if err != nil {
+			t.Fatalf("%s: expected no error but got %v", c.name, err)
+		}
+		if ver != "id3v2.3.0" {
+			t.Errorf("%s: expected version id3v2.3.0 but got %s", c.name, ver)
+		}
+		if data := tag.Frames()["TIT2"]; !bytes.Equal(data, []byte("\x00Test")) {
+			t.Errorf("%s: expected TIT2 frame %q but got %q", c.name, "\x00Test", data)
+		}
+	}
+}
+
+func TestDecodeFromWAVNoChunk(t *testing.T) {
+	body := append([]byte("WAVE"), chunk(binary.LittleEndian, "data", []byte{0, 0})...)
+	file := chunk(binary.LittleEndian, "RIFF", body)
+
+	if _, _, err := id3v2.DecodeFromWAV(bytes.NewReader(file)); err != id3v2.ErrNoChunk {
+		t.Errorf("expected ErrNoChunk but got %v", err)
+	}
+}
+
+func TestDecodeUTF8BOM(t *testing.T) {
+	b := append([]byte{0xEF, 0xBB, 0xBF}, testTag...)
+
+	tag, _, err := id3v2.Decode(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data := tag.Frames()["TIT2"]; !bytes.Equal(data, []byte("\x00Test")) {
+		t.Errorf("expected TIT2 frame %q but got %q", "\x00Test", data)
+	}
+}
@@ -0,0 +1,192 @@
+package id3v2
+
+import (
+	"io"
+	"strings"
+)
+
+// ID3v2.3.0 and ID3v2.4.0 frame flags as far as converting them is
+// concerned. The status flags of ID3v2.4.0 sit one bit lower.
+const (
+	v23StatusFlags = uint16(0xE000) // Tag alter, file alter and read only
+	v23Compression = uint16(1 << 7)
+	v23Encryption  = uint16(1 << 6)
+
+	v24StatusFlags = uint16(0x7000)
+	v24Compression = uint16(1 << 3)
+	v24Encryption  = uint16(1 << 2)
+)
+
+// EncodeAs encodes tag as the given version, e.g. "id3v2.3.0", rather than its
+// own. It returns ErrVersion if the version isn't registered.
+//
+// ID3v2.4.0 tags written as ID3v2.3.0 are converted as far as possible:
+//
+//   - TDRC is split into TYER, TDAT and TIME, TDOR becomes TORY and TIPL
+//     becomes IPLS
+//   - Text in UTF-8 or UTF-16BE is re-encoded, and the null separated values
+//     of text frames are joined with "/"
+//   - Frames with no ID3v2.3.0 equivalent are dropped
+//
+// Between any two versions only the status flags of frames are kept, moved to
+// the bits of the version, and compressed or encrypted frames are dropped
+// since their data can't be carried over. Frames are otherwise copied as they
+// are, unsupported frames still passed through, leaving the encoder of the
+// version to reject the ones it can't write.
+func EncodeAs(w io.Writer, tag Tag, v string) error {
+	ver, err := lookupEncoder(v)
+	if err != nil {
+		return err
+	}
+
+	src, ok := versionOf(tag)
+	if ok && src.major == ver.major && src.revision == ver.revision {
+		return ver.encode(w, tag)
+	}
+	from, _ := tag.Version()
+	downgrade := from == 4 && ver.major == 3
+
+	out := ver.newTag()
+	for i := range tag.FrameOrder() {
+		f := tag.FrameAt(i)
+
+		flags, ok := convertFlags(from, ver.major, f.Flags)
+		if !ok {
+			continue
+		}
+
+		fs := []idFrame{{f.ID, f.Data}}
+		if downgrade {
+			fs = downgradeFrame(f.ID, f.Data)
+		}
+		for _, d := range fs {
+			out.AppendFrame(Frame{ID: d.id, Flags: flags, Data: d.data, Raw: f.Raw && d.id == f.ID})
+		}
+	}
+
+	return ver.encode(w, out)
+}
+
+// convertFlags converts frame flags from the layout of one major version to
+// another's, keeping only the status flags. It returns false for compressed
+// or encrypted frames.
+func convertFlags(from, to byte, flags uint16) (uint16, bool) {
+	var status uint16
+	switch from {
+	case 3:
+		if flags&(v23Compression|v23Encryption) != 0 {
+			return 0, false
+		}
+		status = (flags & v23StatusFlags) >> 1
+	case 4:
+		if flags&(v24Compression|v24Encryption) != 0 {
+			return 0, false
+		}
+		status = flags & v24StatusFlags
+	}
+
+	switch to {
+	case 3:
+		return status << 1, true
+	case 4:
+		return status, true
+	}
+	return 0, true
+}
+
+// idFrame is a frame ID and its data.
+type idFrame struct {
+	id   string
+	data []byte
+}
+
+// downgradeFrame converts an ID3v2.4.0 frame to the ID3v2.3.0 frames holding
+// the same information. Frames that fail to decode are returned as they are,
+// or dropped if they would have been renamed.
+func downgradeFrame(id string, data []byte) []idFrame {
+	switch id {
+	case "TDRC":
+		// yyyy-MM-ddTHH:mm:ss, truncated to any precision
+		s, err := DecodeTextFrame(data)
+		if err != nil {
+			return nil
+		}
+		var fs []idFrame
+		if len(s) >= 4 {
+			fs = append(fs, idFrame{"TYER", EncodeTextFrame(s[:4], EncodingISO88591)})
+		}
+		if len(s) >= 10 {
+			fs = append(fs, idFrame{"TDAT", EncodeTextFrame(s[8:10]+s[5:7], EncodingISO88591)})
+		}
+		if len(s) >= 16 {
+			fs = append(fs, idFrame{"TIME", EncodeTextFrame(s[11:13]+s[14:16], EncodingISO88591)})
+		}
+		return fs
+
+	case "TDOR":
+		if s, err := DecodeTextFrame(data); err == nil && len(s) >= 4 {
+			return []idFrame{{"TORY", EncodeTextFrame(s[:4], EncodingISO88591)}}
+		}
+		return nil
+
+	case "TIPL":
+		// IPLS keeps the null separated pairs
+		if s, err := DecodeTextFrame(data); err == nil {
+			return []idFrame{{"IPLS", EncodeTextFrame(s, EncodingAuto)}}
+		}
+		return nil
+	}
+
+	if frames240Only[id] {
+		return nil
+	}
+
+	if len(data) < 1 {
+		return []idFrame{{id, data}}
+	}
+	reencode := Encoding(data[0]) == EncodingUTF16BE || Encoding(data[0]) == EncodingUTF8
+
+	var b []byte
+	var err error
+	switch {
+	case !reencode && (id == "TXXX" || !strings.HasPrefix(id, "T")):
+		// Only the values of text frames need joining otherwise
+		b = data
+
+	case id == "TXXX":
+		var desc, value string
+		if desc, value, err = ParseTXXX(data); err == nil {
+			b, err = EncodeTXXX(desc, value, EncodingAuto)
+		}
+
+	case id == "WXXX":
+		var desc, url string
+		if desc, url, err = ParseWXXX(data); err == nil {
+			b, err = EncodeWXXX(desc, url, EncodingAuto)
+		}
+
+	case id == "COMM" || id == "USLT":
+		// COMM frames share the layout of USLT frames
+		var l Lyrics
+		if l.Language, l.Description, l.Text, err = parseCOMM(data); err == nil {
+			l.Encoding = EncodingAuto
+			b, err = EncodeUSLT(l)
+		}
+
+	case strings.HasPrefix(id, "T"):
+		var s string
+		if s, err = DecodeTextFrame(data); err == nil && (reencode || strings.Contains(s, "\x00")) {
+			b = EncodeTextFrame(strings.Replace(s, "\x00", "/", -1), EncodingAuto)
+		} else {
+			b = data
+		}
+
+	default:
+		b = data
+	}
+	if err != nil {
+		b = data
+	}
+
+	return []idFrame{{id, b}}
+}
@@ -0,0 +1,119 @@
+package id3v2_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/jlubawy/go-id3v2"
+	_ "github.com/jlubawy/go-id3v2/id3v230"
+	_ "github.com/jlubawy/go-id3v2/id3v240"
+)
+
+func TestEncodeAsDowngrade(t *testing.T) {
+	tag, err := id3v2.NewTag("id3v2.4.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tag.SetFrame("TIT2", []byte("\x03Caf\xC3\xA9\x00"))
+	tag.SetFrameFlags("TIT2", 1<<12) // Read only
+	tag.SetFrame("TPE1", []byte("\x00One\x00Two"))
+	tag.SetFrame("TDRC", []byte("\x002004-05-06T07:08"))
+	tag.SetFrame("TSOA", []byte("\x00Album Sort"))
+	tag.SetFrame("TXXX", []byte("\x03Desc\x00Value"))
+	tag.SetFrame("PRIV", []byte("owner\x00\x03\xFF"))
+
+	buf := &bytes.Buffer{}
+	if err := id3v2.EncodeAs(buf, tag, "id3v2.3.0"); err != nil {
+		t.Fatal(err)
+	}
+
+	out, v, err := id3v2.Decode(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != "id3v2.3.0" {
+		t.Fatalf("expected version id3v2.3.0 but got %s", v)
+	}
+
+	expected := map[string]string{
+		"TIT2": "Café",
+		"TPE1": "One/Two",
+		"TYER": "2004",
+		"TDAT": "0605",
+		"TIME": "0708",
+	}
+	for id, s := range expected {
+		data, ok := out.GetFrame(id)
+		if !ok {
+			t.Errorf("expected a %s frame", id)
+			continue
+		}
+		if data[0] > 1 {
+			t.Errorf("expected %s to be re-encoded but got encoding $%02X", id, data[0])
+		}
+		if got, err := id3v2.DecodeTextFrame(data); err != nil || got != s {
+			t.Errorf("expected %s '%s' but got '%s' (%v)", id, s, got, err)
+		}
+	}
+
+	if desc, value, err := id3v2.ParseTXXX(out.Frames()["TXXX"]); err != nil || desc != "Desc" || value != "Value" {
+		t.Errorf("expected TXXX Desc=Value but got %s=%s (%v)", desc, value, err)
+	}
+	if data := out.Frames()["PRIV"]; !bytes.Equal(data, []byte("owner\x00\x03\xFF")) {
+		t.Errorf("expected PRIV to be copied but got %q", data)
+	}
+	for _, id := range []string{"TDRC", "TSOA"} {
+		if _, ok := out.GetFrame(id); ok {
+			t.Errorf("expected %s to be dropped", id)
+		}
+	}
+	if flags := out.FrameFlags("TIT2"); flags != 1<<13 {
+		t.Errorf("expected the ID3v2.3.0 read only flag but got %04X", flags)
+	}
+}
+
+func TestEncodeAsUnknownVersion(t *testing.T) {
+	tag, err := id3v2.NewTag("id3v2.4.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := id3v2.EncodeAs(&bytes.Buffer{}, tag, "id3v2.9.0"); err != id3v2.ErrVersion {
+		t.Errorf("expected ErrVersion but got %v", err)
+	}
+}
+
+func TestEncodeAsUpgrade(t *testing.T) {
+	b := []byte{
+		'I', 'D', '3', 3, 0, 0, 0, 0, 0, 52,
+		'Z', 'Z', 'Z', 'Z', 0, 0, 0, 4, 0x20, 0, // Read only
+		'a', 'b', 'c', 'd',
+		'T', 'I', 'T', '2', 0, 0, 0, 9, 0, 0x80, // Compressed
+		0, 0, 0, 5, 0x78, 0x9C, 0x01, 0x02, 0x03,
+		'T', 'P', 'E', '1', 0, 0, 0, 9, 0x80, 0x20, // Tag alter, grouped
+		0x01, 0, 'A', 'r', 't', 'i', 's', 't', 0,
+	}
+
+	tag, _, err := id3v2.Decode(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := id3v2.EncodeAs(buf, tag, "id3v2.4.0"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The compressed frame is dropped, the status flags move down a bit and
+	// the group identifier is left out along with its flag
+	expected := []byte{
+		'I', 'D', '3', 4, 0, 0, 0, 0, 0, 32,
+		'Z', 'Z', 'Z', 'Z', 0, 0, 0, 4, 0x10, 0,
+		'a', 'b', 'c', 'd',
+		'T', 'P', 'E', '1', 0, 0, 0, 8, 0x40, 0,
+		0, 'A', 'r', 't', 'i', 's', 't', 0,
+	}
+	if !bytes.Equal(buf.Bytes(), expected) {
+		t.Errorf("expected %v but got %v", expected, buf.Bytes())
+	}
+}
@@ -0,0 +1,112 @@
+package id3v2
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+)
+
+// minCounterSize is the smallest size of the play counter of PCNT and POPM
+// frames, which grows by a byte whenever it's about to overflow.
+const minCounterSize = 4
+
+// parseCounter reads a big-endian play counter of any size. It returns false
+// if the counter doesn't fit in a uint64, ignoring leading zero bytes.
+func parseCounter(b []byte) (uint64, bool) {
+	for len(b) > 8 && b[0] == 0 {
+		b = b[1:]
+	}
+	if len(b) > 8 {
+		return math.MaxUint64, false
+	}
+
+	var n uint64
+	for _, c := range b {
+		n = n<<8 | uint64(c)
+	}
+	return n, true
+}
+
+// encodeCounter encodes a play counter in as few bytes as it fits in, but no
+// fewer than minCounterSize.
+func encodeCounter(n uint64) []byte {
+	b := make([]byte, 8)
+	for i := len(b) - 1; i >= 0; i-- {
+		b[i] = byte(n)
+		n >>= 8
+	}
+
+	for len(b) > minCounterSize && b[0] == 0 {
+		b = b[1:]
+	}
+	return b
+}
+
+// Popularimeter is a popularimeter frame (POPM), a rating and play counter
+// kept by a player for its user.
+//
+// Email to user   <text string> $00
+// Rating          $xx
+// Counter         $xx xx xx xx (xx ...)
+type Popularimeter struct {
+	Email  string
+	Rating byte // 1 is worst and 255 best, 0 is unknown
+
+	// Counter is 0 if the frame has no counter. Counters too large for a
+	// uint64 are given as math.MaxUint64.
+	Counter uint64
+}
+
+// ParsePOPM parses the data of a POPM frame.
+func ParsePOPM(data []byte) (Popularimeter, error) {
+	var p Popularimeter
+
+	i := bytes.IndexByte(data, 0)
+	if i < 0 || i+1 >= len(data) {
+		return p, errShortFrame("POPM")
+	}
+
+	var err error
+	if p.Email, err = decodeString(encodingISO88591, data[:i]); err != nil {
+		return p, err
+	}
+	p.Rating = data[i+1]
+	p.Counter, _ = parseCounter(data[i+2:])
+
+	return p, nil
+}
+
+// EncodePOPM encodes a popularimeter as the data of a POPM frame. The counter
+// is left out if it's 0. The email must be ISO-8859-1.
+func EncodePOPM(p Popularimeter) ([]byte, error) {
+	if !isISO88591(p.Email) {
+		return nil, fmt.Errorf("id3v2: POPM email must be ISO-8859-1 but got '%s'", p.Email)
+	}
+
+	b := append(encodeString(EncodingISO88591, p.Email), 0, p.Rating)
+	if p.Counter > 0 {
+		b = append(b, encodeCounter(p.Counter)...)
+	}
+	return b, nil
+}
+
+// ParsePCNT parses the data of a PCNT frame, a play counter of at least 4
+// bytes. It returns an error if the counter doesn't fit in a uint64 rather
+// than truncating it.
+func ParsePCNT(data []byte) (uint64, error) {
+	if len(data) < minCounterSize {
+		return 0, errShortFrame("PCNT")
+	}
+
+	n, ok := parseCounter(data)
+	if !ok {
+		return 0, fmt.Errorf("id3v2: PCNT counter of %d bytes doesn't fit in 64 bits", len(data))
+	}
+	return n, nil
+}
+
+// EncodePCNT encodes a play count as the data of a PCNT frame, in as few
+// bytes as it fits in but no fewer than 4.
+func EncodePCNT(count uint64) []byte {
+	return encodeCounter(count)
+}
@@ -0,0 +1,18 @@
+package id3v2
+
+import (
+	"encoding/hex"
+	"fmt"
+)
+
+// HexDump returns a hex and ASCII dump of a frame's data in the format of
+// `hexdump -C`, preceded by the frame ID and size. It returns an empty string
+// if the tag has no such frame.
+func HexDump(tag Tag, id string) string {
+	data, ok := tag.Frames()[id]
+	if !ok {
+		return ""
+	}
+
+	return fmt.Sprintf("%s (%d bytes)\n%s", id, len(data), hex.Dump(data))
+}
@@ -0,0 +1,25 @@
+package id3v2_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/jlubawy/go-id3v2"
+)
+
+func TestHexDump(t *testing.T) {
+	tag, _, err := id3v2.Decode(bytes.NewReader(testTag))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "TIT2 (5 bytes)\n" +
+		"00000000  00 54 65 73 74                                    |.Test|\n"
+	if s := id3v2.HexDump(tag, "TIT2"); s != expected {
+		t.Errorf("expected dump\n%s\nbut got\n%s", expected, s)
+	}
+
+	if s := id3v2.HexDump(tag, "TPE1"); s != "" {
+		t.Errorf("expected empty dump for a missing frame but got %q", s)
+	}
+}
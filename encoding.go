@@ -0,0 +1,108 @@
+package id3v2
+
+import (
+	"fmt"
+	"unicode/utf16"
+)
+
+// An Encoding is a text encoding, given by the first byte of frames
+// containing text.
+type Encoding byte
+
+const (
+	EncodingISO88591 = Encoding(0x00)
+	EncodingUTF16    = Encoding(0x01) // With BOM
+	EncodingUTF16BE  = Encoding(0x02) // ID3v2.4 only
+	EncodingUTF8     = Encoding(0x03) // ID3v2.4 only
+
+	// EncodingAuto picks ISO-8859-1 if the text can be represented in it and
+	// UTF-16 otherwise, which every version supports. It's never written.
+	EncodingAuto = Encoding(0xFF)
+)
+
+// EncodeTextFrame encodes s as the data of a text information frame such as
+// TIT2, starting with the encoding byte and ending with a null terminator.
+// UTF-16 is written little-endian with a BOM. Characters that can't be
+// represented in ISO-8859-1 are replaced with '?'. An unknown encoding is
+// treated as EncodingAuto rather than written into the frame.
+func EncodeTextFrame(s string, enc Encoding) []byte {
+	if enc > EncodingUTF8 {
+		enc = EncodingAuto
+	}
+	enc = resolveEncoding(enc, s)
+
+	b := []byte{byte(enc)}
+	b = append(b, encodeString(enc, s)...)
+	return append(b, terminator(enc)...)
+}
+
+// resolveEncoding returns the encoding EncodingAuto stands for given every
+// string of a frame, or enc if it's not EncodingAuto.
+func resolveEncoding(enc Encoding, strs ...string) Encoding {
+	if enc != EncodingAuto {
+		return enc
+	}
+	for _, s := range strs {
+		if !isISO88591(s) {
+			return EncodingUTF16
+		}
+	}
+	return EncodingISO88591
+}
+
+// checkEncoding returns an error unless enc is a text encoding that can be
+// written.
+func checkEncoding(id string, enc Encoding) error {
+	if enc > EncodingUTF8 {
+		return fmt.Errorf("id3v2: %s has unknown text encoding $%02X", id, byte(enc))
+	}
+	return nil
+}
+
+// encodeString encodes s without a null terminator.
+func encodeString(enc Encoding, s string) []byte {
+	var b []byte
+
+	switch enc {
+	case EncodingUTF16:
+		b = encodeUTF16(s)
+
+	case EncodingUTF16BE:
+		for _, u := range utf16.Encode([]rune(s)) {
+			b = append(b, byte(u>>8), byte(u))
+		}
+
+	case EncodingUTF8:
+		b = []byte(s)
+
+	default:
+		for _, r := range s {
+			if r > 0xFF {
+				r = '?'
+			}
+			b = append(b, byte(r))
+		}
+	}
+
+	return b
+}
+
+// terminator returns the null terminator of strings in the given encoding,
+// which is two bytes wide for the UTF-16 encodings.
+func terminator(enc Encoding) []byte {
+	if enc == EncodingUTF16 || enc == EncodingUTF16BE {
+		return []byte{0, 0}
+	}
+	return []byte{0}
+}
+
+// isISO88591 returns true if every character of s can be represented in
+// ISO-8859-1.
+func isISO88591(s string) bool {
+	for _, r := range s {
+		if r > 0xFF {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,58 @@
+package id3v2_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/jlubawy/go-id3v2"
+	"github.com/jlubawy/go-id3v2/id3v230"
+)
+
+func TestEncodeTextFrame(t *testing.T) {
+	tests := []struct {
+		s   string
+		enc id3v2.Encoding
+		b   byte
+	}{
+		{"Café", id3v2.EncodingAuto, 0x00},
+		{"Snow ☃", id3v2.EncodingAuto, 0x01},
+		{"Café", id3v2.EncodingISO88591, 0x00},
+		{"Snow ☃", id3v2.EncodingUTF16, 0x01},
+		{"Snow ☃", id3v2.EncodingUTF16BE, 0x02},
+		{"Snow ☃", id3v2.EncodingUTF8, 0x03},
+		{"Café", id3v2.Encoding(0x05), 0x00},
+		{"Snow ☃", id3v2.Encoding(0x05), 0x01},
+	}
+
+	for _, test := range tests {
+		data := id3v2.EncodeTextFrame(test.s, test.enc)
+		if data[0] != test.b {
+			t.Errorf("%s: expected encoding byte $%02X but got $%02X", test.s, test.b, data[0])
+		}
+
+		tag := id3v230.NewTag()
+		tag.SetFrame("TIT2", data)
+
+		buf := &bytes.Buffer{}
+		if err := id3v230.Encode(buf, tag); err != nil {
+			t.Fatal(err)
+		}
+
+		tag, err := id3v230.Decode(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		s, err := id3v2.DecodeTextFrame(tag.Frames()["TIT2"])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s != test.s {
+			t.Errorf("expected '%s' but got '%s'", test.s, s)
+		}
+	}
+
+	if s := string(id3v2.EncodeTextFrame("☃", id3v2.EncodingISO88591)); s != "\x00?\x00" {
+		t.Errorf("expected '\\x00?\\x00' but got %q", s)
+	}
+}
@@ -0,0 +1,100 @@
+package id3v2
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// Field length limits given by the specification.
+const (
+	LanguageLength        = 3  // ISO-639-2 language code
+	MaxUFIDIdentifierSize = 64 // UFID identifier
+	DateLength            = 8  // YYYYMMDD date
+)
+
+// checkLanguage returns an error unless lang is an ISO-639-2 language code,
+// three letters such as "eng" or "XXX" for an unknown language.
+func checkLanguage(id, lang string) error {
+	if len(lang) != LanguageLength {
+		return fmt.Errorf("id3v2: %s language must be exactly %d bytes but got %d", id, LanguageLength, len(lang))
+	}
+	for _, c := range []byte(lang) {
+		if (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') {
+			return fmt.Errorf("id3v2: %s language must be %d letters but got %q", id, LanguageLength, lang)
+		}
+	}
+	return nil
+}
+
+// checkUFIDIdentifier returns an error if a UFID identifier is too long.
+func checkUFIDIdentifier(identifier []byte) error {
+	if len(identifier) > MaxUFIDIdentifierSize {
+		return fmt.Errorf("id3v2: UFID identifier must be at most %d bytes but got %d", MaxUFIDIdentifierSize, len(identifier))
+	}
+	return nil
+}
+
+// checkDate returns an error unless date is in the YYYYMMDD format.
+func checkDate(id, field, date string) error {
+	if len(date) != DateLength {
+		return fmt.Errorf("id3v2: %s %s must be exactly %d characters but got %d", id, field, DateLength, len(date))
+	}
+	for _, c := range date {
+		if c < '0' || c > '9' {
+			return fmt.Errorf("id3v2: %s %s must be numeric but got '%s'", id, field, date)
+		}
+	}
+	return nil
+}
+
+// validateFieldLengths reports fields of existing frames that exceed the
+// limits of the specification.
+func validateFieldLengths(tag Tag) []error {
+	var errs []error
+
+	for _, id := range []string{"COMM", "USLT", "USER"} {
+		for _, data := range tag.FramesByID(id) {
+			if len(data) < 1+LanguageLength {
+				errs = append(errs, errShortFrame(id))
+			} else if err := checkLanguage(id, string(data[1:1+LanguageLength])); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	for _, data := range tag.FramesByID("UFID") {
+		if _, id, err := ParseUFID(data); err == nil {
+			if err := checkUFIDIdentifier(id); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	// Text encoding   $xx
+	// Price paid      <text string> $00
+	// Date of purch.  <text string>
+	for _, data := range tag.FramesByID("OWNE") {
+		if len(data) < 1 {
+			continue
+		}
+		if i := bytes.IndexByte(data[1:], 0); i >= 0 {
+			date := data[1+i+1:]
+			if len(date) > DateLength {
+				date = date[:DateLength]
+			}
+			if err := checkDate("OWNE", "date of purchase", string(date)); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	for _, data := range tag.FramesByID("COMR") {
+		if c, err := ParseCOMR(data); err == nil {
+			if err := checkDate("COMR", "valid until", c.ValidUntil); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,53 @@
+package id3v2
+
+import (
+	"testing"
+)
+
+func TestFieldChecks(t *testing.T) {
+	if err := checkLanguage("COMM", "eng"); err != nil {
+		t.Errorf("expected no error for language eng but got %v", err)
+	}
+	if err := checkLanguage("COMM", "en"); err == nil {
+		t.Error("expected an error for a 2 byte language")
+	}
+	if err := checkLanguage("COMM", "e\x00g"); err == nil {
+		t.Error("expected an error for a language that isn't letters")
+	}
+
+	if err := checkUFIDIdentifier(make([]byte, 64)); err != nil {
+		t.Errorf("expected no error for a 64 byte identifier but got %v", err)
+	}
+	if err := checkUFIDIdentifier(make([]byte, 65)); err == nil {
+		t.Error("expected an error for a 65 byte identifier")
+	}
+
+	if err := checkDate("OWNE", "date of purchase", "20170102"); err != nil {
+		t.Errorf("expected no error for date 20170102 but got %v", err)
+	}
+	for _, date := range []string{"2017012", "2017-1-2"} {
+		if err := checkDate("OWNE", "date of purchase", date); err == nil {
+			t.Errorf("expected an error for date %s", date)
+		}
+	}
+}
+
+func TestEncodersCheckFields(t *testing.T) {
+	if _, err := EncodeCOMM(Comment{Encoding: EncodingAuto, Language: "en", Text: "Nice"}); err == nil {
+		t.Error("expected an error encoding a COMM frame with a 2 byte language")
+	}
+	if _, err := EncodeUSLT(Lyrics{Encoding: EncodingAuto, Language: "12\x00", Text: "La"}); err == nil {
+		t.Error("expected an error encoding a USLT frame with a language that isn't letters")
+	}
+	if _, err := EncodeUSER(TermsOfUse{Encoding: EncodingAuto, Language: "english", Text: "Terms"}); err == nil {
+		t.Error("expected an error encoding a USER frame with a 7 byte language")
+	}
+
+	data, err := EncodeUSER(TermsOfUse{Encoding: EncodingAuto, Language: "eng", Text: "Terms"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u, err := ParseUSER(data); err != nil || u.Language != "eng" || u.Text != "Terms" {
+		t.Errorf("expected terms of use 'Terms' in eng but got %+v (%v)", u, err)
+	}
+}
@@ -0,0 +1,257 @@
+package id3v2
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// Update edits the tag of the file at path in one call. It decodes the
+// existing tag, or creates an empty tag of the latest registered version if
+// the file has none, calls mutate to edit it and then writes it back. The new
+// tag overwrites the old one in place if it fits within the old tag and its
+// padding, otherwise the file is rewritten with the audio preserved as is. An
+// ID3v2.4 tag with a footer can't have padding, so it only overwrites the old
+// tag if it's exactly the same size.
+func Update(path string, mutate func(Tag) error) error {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	tag, v, oldSize, err := decodeFile(f)
+	if err != nil {
+		return err
+	}
+
+	if err := mutate(tag); err != nil {
+		return err
+	}
+
+	ver, err := lookupEncoder(v)
+	if err != nil {
+		return err
+	}
+
+	return writeTag(f, path, tag, ver, oldSize)
+}
+
+// WriteToFile writes tag to the file at path in place of its existing tag, if
+// it has one, preserving the audio byte for byte. The tag is encoded in its
+// own version. Like Update, the new tag overwrites the old one in place if it
+// fits within the old tag and its padding, otherwise the file is rewritten.
+func WriteToFile(path string, tag Tag) error {
+	ver, ok := versionOf(tag)
+	if !ok {
+		return errVersion()
+	}
+
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	oldSize, err := AudioOffset(f)
+	if err != nil {
+		return err
+	}
+
+	return writeTag(f, path, tag, ver, oldSize)
+}
+
+// writeTag encodes tag and writes it to f in place of the old tag of oldSize
+// bytes.
+func writeTag(f *os.File, path string, tag Tag, ver version, oldSize int64) error {
+	buf := &bytes.Buffer{}
+	if err := ver.encode(buf, tag); err != nil {
+		return err
+	}
+
+	// ID3v2.4 tags with a footer must not have padding, so they only
+	// overwrite the old tag if they fill it exactly
+	b := buf.Bytes()
+	footer := b[3] >= 4 && b[5]&headerFlagFooterPresent != 0
+	if oldSize > 0 && (int64(len(b)) == oldSize || (int64(len(b)) < oldSize && !footer)) {
+		return overwriteTag(f, b, oldSize)
+	}
+
+	return rewriteFile(f, path, buf.Bytes(), oldSize)
+}
+
+// QuickInfo returns the title, artist and album of the file at path in one
+// call. Missing frames give empty strings, an error is only returned if the
+// file can't be read or has no tag that can be decoded.
+func QuickInfo(path string) (title, artist, album string, err error) {
+	tag, _, err := DecodeFile(path)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	title, _ = Title(tag)
+	artist, _ = Artist(tag)
+	album, _ = Album(tag)
+
+	return title, artist, album, nil
+}
+
+// id3v1Size is the size of an ID3v1 tag, found at the end of a file.
+const id3v1Size = 128
+
+// DecodeFile decodes the tag at the start of the file at path, returning the
+// version like Decode. Decoding never reads into a trailing ID3v1 tag, so a
+// tag whose size runs into it is reported as truncated rather than misread.
+func DecodeFile(path string) (Tag, string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, "", err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, "", err
+	}
+	if size := fi.Size(); size >= id3v1Size {
+		var id [3]byte
+		if _, err := f.ReadAt(id[:], size-id3v1Size); err != nil {
+			return nil, "", err
+		}
+		if string(id[:]) == "TAG" {
+			r = io.LimitReader(f, size-id3v1Size)
+		}
+	}
+
+	return Decode(r)
+}
+
+// HasTag returns true if r starts with the ID3v2 file identifier, without
+// decoding the tag.
+func HasTag(r io.ReaderAt) bool {
+	id := make([]byte, len(FileIdentifier))
+	if _, err := r.ReadAt(id, 0); err != nil {
+		return false
+	}
+	return bytes.Equal(id, FileIdentifier)
+}
+
+// decodeFile decodes the tag at the start of f, returning an empty tag of the
+// latest registered version if there isn't one, and the size of the tag on
+// disk. A UTF-8 BOM before the tag counts towards its size, so that it's
+// replaced along with the tag.
+func decodeFile(f *os.File) (Tag, string, int64, error) {
+	b := make([]byte, len(utf8BOM)+headerSize)
+
+	n, err := io.ReadFull(f, b)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, "", 0, err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, "", 0, err
+	}
+
+	h, bom := trimBOM(b[:n])
+	if len(h) < headerSize || !bytes.Equal(h[0:3], FileIdentifier) {
+		v, err := latestVersion()
+		if err != nil {
+			return nil, "", 0, err
+		}
+		tag, err := NewTag(v)
+		return tag, v, 0, err
+	}
+
+	tag, v, err := Decode(f)
+	if err != nil {
+		return nil, v, 0, err
+	}
+
+	return tag, v, bom + int64(tagSize(h)), nil
+}
+
+// latestVersion returns the version string of the latest registered version
+// which can encode tags.
+func latestVersion() (string, error) {
+	var latest *version
+	for i, ver := range versions {
+		if !ver.canEncode() {
+			continue
+		}
+		if latest == nil || ver.major > latest.major || (ver.major == latest.major && ver.revision > latest.revision) {
+			latest = &versions[i]
+		}
+	}
+	if latest == nil {
+		return "", errVersion()
+	}
+	return fmt.Sprintf("id3v2.%d.%d", latest.major, latest.revision), nil
+}
+
+// overwriteTag writes an encoded tag over an old tag of size bytes, turning
+// the bytes left over into padding by growing the size in the header. The tag
+// must not have a footer unless it's exactly size bytes.
+func overwriteTag(f *os.File, tag []byte, size int64) error {
+	b := make([]byte, size)
+	copy(b, tag)
+	binary.BigEndian.PutUint32(b[6:10], SizeToSynchSafe(uint32(size-headerSize)))
+
+	// The extended header of ID3v2.3 tags declares the padding size after
+	// its own size and flags, which grows by the same bytes
+	if b[3] == 3 && b[5]&headerFlagExtendedHeader != 0 && len(tag) >= headerSize+10 {
+		padding := binary.BigEndian.Uint32(b[16:20]) + uint32(size-int64(len(tag)))
+		binary.BigEndian.PutUint32(b[16:20], padding)
+	}
+
+	if _, err := f.WriteAt(b, 0); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+// rewriteFile replaces the file at path with the encoded tag followed by the
+// audio of f, which starts after the old tag of size bytes. The new file is
+// written alongside the old one and renamed over it once complete.
+func rewriteFile(f *os.File, path string, tag []byte, size int64) error {
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".id3v2-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	w := bufio.NewWriter(tmp)
+	if _, err := w.Write(tag); err != nil {
+		return err
+	}
+
+	if _, err := f.Seek(size, io.SeekStart); err != nil {
+		return err
+	}
+	if _, err := io.Copy(w, f); err != nil {
+		return err
+	}
+
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if err := tmp.Chmod(fi.Mode()); err != nil {
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
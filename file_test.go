@@ -0,0 +1,296 @@
+package id3v2_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jlubawy/go-id3v2"
+	"github.com/jlubawy/go-id3v2/id3v230"
+	"github.com/jlubawy/go-id3v2/id3v240"
+)
+
+var testAudio = []byte{0xFF, 0xFB, 0x90, 0x00, 0x01, 0x02, 0x03, 0x04}
+
+// writeTestFile writes the given tag and test audio to a temporary file.
+func writeTestFile(t *testing.T, tag []byte) string {
+	dir, err := ioutil.TempDir("", "id3v2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, "test.mp3")
+	if err := ioutil.WriteFile(path, append(append([]byte{}, tag...), testAudio...), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestUpdate(t *testing.T) {
+	cases := []struct {
+		name  string
+		title string
+	}{
+		{"in place", "Tst"},
+		{"rewrite", "A much longer title"},
+	}
+
+	for _, c := range cases {
+		path := writeTestFile(t, testTag)
+		defer os.RemoveAll(filepath.Dir(path))
+
+		err := id3v2.Update(path, func(tag id3v2.Tag) error {
+			tag.SetFrame("TIT2", append([]byte{0}, c.title...))
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		tag, _, err := id3v2.Decode(bytes.NewReader(b))
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if data := tag.Frames()["TIT2"]; string(data[1:]) != c.title {
+			t.Errorf("%s: expected title %q but got %q", c.name, c.title, data[1:])
+		}
+		if !bytes.HasSuffix(b, testAudio) {
+			t.Errorf("%s: expected audio to be preserved", c.name)
+		}
+		if c.name == "in place" && len(b) != len(testTag)+len(testAudio) {
+			t.Errorf("%s: expected file size to be unchanged but got %d", c.name, len(b))
+		}
+	}
+}
+
+func TestUpdateBOM(t *testing.T) {
+	bom := []byte{0xEF, 0xBB, 0xBF}
+
+	for _, title := range []string{"Tst", "A much longer title"} {
+		path := writeTestFile(t, append(append([]byte{}, bom...), testTag...))
+		defer os.RemoveAll(filepath.Dir(path))
+
+		err := id3v2.Update(path, func(tag id3v2.Tag) error {
+			tag.SetFrame("TIT2", append([]byte{0}, title...))
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("%s: %v", title, err)
+		}
+
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// The BOM is replaced along with the old tag rather than a second
+		// tag being written in front of it
+		if n := bytes.Count(b, id3v2.FileIdentifier); n != 1 || !bytes.HasPrefix(b, id3v2.FileIdentifier) {
+			t.Errorf("%s: expected a single tag at the start of the file but got %d", title, n)
+		}
+
+		tag, _, err := id3v2.Decode(bytes.NewReader(b))
+		if err != nil {
+			t.Fatalf("%s: %v", title, err)
+		}
+		if data := tag.Frames()["TIT2"]; string(data[1:]) != title {
+			t.Errorf("%s: expected title %q but got %q", title, title, data[1:])
+		}
+		if !bytes.HasSuffix(b, testAudio) {
+			t.Errorf("%s: expected audio to be preserved", title)
+		}
+	}
+}
+
+func TestQuickInfo(t *testing.T) {
+	b := []byte{
+		'I', 'D', '3', 3, 0, 0, 0, 0, 0, 32,
+		'T', 'I', 'T', '2', 0, 0, 0, 5, 0, 0,
+		0, 'T', 'e', 's', 't',
+		'T', 'A', 'L', 'B', 0, 0, 0, 7, 0, 0,
+		1, 0xFF, 0xFE, 'L', 0, 'P', 0,
+	}
+
+	path := writeTestFile(t, b)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	title, artist, album, err := id3v2.QuickInfo(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if title != "Test" || artist != "" || album != "LP" {
+		t.Errorf("expected 'Test', '' and 'LP' but got '%s', '%s' and '%s'", title, artist, album)
+	}
+
+	path = writeTestFile(t, nil)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	if _, _, _, err := id3v2.QuickInfo(path); err != id3v2.ErrFormat {
+		t.Errorf("expected ErrFormat for a file without a tag but got %v", err)
+	}
+}
+
+func TestDecodeFile(t *testing.T) {
+	v1 := make([]byte, 128)
+	copy(v1, "TAG")
+
+	path := writeTestFile(t, testTag)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write(v1); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	tag, v, err := id3v2.DecodeFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != "id3v2.3.0" {
+		t.Errorf("expected version id3v2.3.0 but got %s", v)
+	}
+	if s, err := id3v2.Title(tag); err != nil || s != "Test" {
+		t.Errorf("expected title 'Test' but got '%s' (%v)", s, err)
+	}
+
+	if _, _, err := id3v2.DecodeFile(filepath.Join(filepath.Dir(path), "missing.mp3")); !os.IsNotExist(err) {
+		t.Errorf("expected a not exist error but got %v", err)
+	}
+}
+
+func TestHasTag(t *testing.T) {
+	if !id3v2.HasTag(bytes.NewReader(append(append([]byte{}, testTag...), testAudio...))) {
+		t.Error("expected a tag")
+	}
+	if id3v2.HasTag(bytes.NewReader(testAudio)) {
+		t.Error("expected no tag in audio")
+	}
+	if id3v2.HasTag(bytes.NewReader([]byte("ID"))) {
+		t.Error("expected no tag in a short input")
+	}
+}
+
+func TestWriteToFile(t *testing.T) {
+	cases := []struct {
+		name  string
+		old   []byte
+		title string
+		size  int // expected file size, or 0 if it's rewritten
+	}{
+		{"shrink", testTag, "Tst", len(testTag) + len(testAudio)},
+		{"grow", testTag, "A much longer title", 0},
+		{"first time", nil, "New", 0},
+	}
+
+	for _, c := range cases {
+		path := writeTestFile(t, c.old)
+		defer os.RemoveAll(filepath.Dir(path))
+
+		tag, err := id3v2.NewTag("id3v2.3.0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		tag.SetFrame("TIT2", append([]byte{0}, c.title...))
+
+		if err := id3v2.WriteToFile(path, tag); err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		decoded, _, err := id3v2.Decode(bytes.NewReader(b))
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if s, err := id3v2.Title(decoded); err != nil || s != c.title {
+			t.Errorf("%s: expected title %q but got %q (%v)", c.name, c.title, s, err)
+		}
+
+		offset, err := id3v2.AudioOffset(bytes.NewReader(b))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(b[offset:], testAudio) {
+			t.Errorf("%s: expected the audio to be preserved but got %v", c.name, b[offset:])
+		}
+		if c.size > 0 && len(b) != c.size {
+			t.Errorf("%s: expected the file size to be unchanged but got %d", c.name, len(b))
+		}
+	}
+}
+
+func TestUpdatePadding(t *testing.T) {
+	footer := []byte{
+		'I', 'D', '3', 4, 0, id3v240.HeaderFlagFooterPresent, 0, 0, 0, 25,
+		'T', 'I', 'T', '2', 0, 0, 0, 5, 0, 0,
+		3, 'T', 'e', 's', 't',
+		0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+		'3', 'D', 'I', 4, 0, id3v240.HeaderFlagFooterPresent, 0, 0, 0, 25,
+	}
+	extended := []byte{
+		'I', 'D', '3', 3, 0, id3v230.HeaderFlagExtendedHeader, 0, 0, 0, 35,
+		0, 0, 0, 6, 0, 0, 0, 0, 0, 10,
+		'T', 'I', 'T', '2', 0, 0, 0, 5, 0, 0,
+		0, 'T', 'e', 's', 't',
+		0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	}
+
+	cases := []struct {
+		name   string
+		old    []byte
+		decode func(b []byte) (id3v2.Tag, error)
+		size   int // expected tag size
+	}{
+		// Padding isn't allowed with a footer so the file is rewritten
+		{"footer", footer, func(b []byte) (id3v2.Tag, error) {
+			return id3v240.Decode(bytes.NewReader(b))
+		}, 34},
+		{"extended header", extended, func(b []byte) (id3v2.Tag, error) {
+			return id3v230.DecodeWithOptions(bytes.NewReader(b), id3v230.DecodeOptions{CheckPaddingSize: true})
+		}, len(extended)},
+	}
+
+	for _, c := range cases {
+		path := writeTestFile(t, c.old)
+		defer os.RemoveAll(filepath.Dir(path))
+
+		err := id3v2.Update(path, func(tag id3v2.Tag) error {
+			data, _ := tag.GetFrame("TIT2")
+			tag.SetFrame("TIT2", append([]byte{data[0]}, "Tst"...))
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		tag, err := c.decode(b)
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if data, _ := tag.GetFrame("TIT2"); string(data[1:]) != "Tst" {
+			t.Errorf("%s: expected title %q but got %q", c.name, "Tst", data[1:])
+		}
+		if len(b) != c.size+len(testAudio) || !bytes.HasSuffix(b, testAudio) {
+			t.Errorf("%s: expected a tag of %d bytes followed by the audio but got %v", c.name, c.size, b)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package id3v2
+
+import (
+	"fmt"
+)
+
+// A FileType is the type of audio file given by a TFLT frame.
+type FileType int
+
+const (
+	FileTypeUnknown FileType = iota
+	FileTypeMPEG             // MPG    MPEG Audio
+	FileTypeMPEG1            // MPG/1  MPEG 1/2 layer I
+	FileTypeMPEG2            // MPG/2  MPEG 1/2 layer II
+	FileTypeMPEG3            // MPG/3  MPEG 1/2 layer III
+	FileTypeMPEG25           // MPG/2.5  MPEG 2.5
+	FileTypeMPEGAAC          // MPG/AAC  Advanced audio compression
+	FileTypeVQF              // VQF    Transform-domain Weighted Interleave Vector Quantization
+	FileTypePCM              // PCM    Pulse Code Modulated audio
+)
+
+var fileTypeCodes = map[string]FileType{
+	"MPG":     FileTypeMPEG,
+	"MPG/1":   FileTypeMPEG1,
+	"MPG/2":   FileTypeMPEG2,
+	"MPG/3":   FileTypeMPEG3,
+	"MPG/2.5": FileTypeMPEG25,
+	"MPG/AAC": FileTypeMPEGAAC,
+	"VQF":     FileTypeVQF,
+	"PCM":     FileTypePCM,
+}
+
+func (ft FileType) String() string {
+	for code, t := range fileTypeCodes {
+		if t == ft {
+			return code
+		}
+	}
+	return "unknown"
+}
+
+// ParseFileType parses the data of a TFLT frame. When the frame isn't present
+// the specification says the type should be assumed to be FileTypeMPEG.
+func ParseFileType(data []byte) (FileType, error) {
+	s, err := DecodeTextFrame(data)
+	if err != nil {
+		return FileTypeUnknown, err
+	}
+
+	ft, ok := fileTypeCodes[s]
+	if !ok {
+		return FileTypeUnknown, fmt.Errorf("id3v2: unknown file type '%s'", s)
+	}
+	return ft, nil
+}
@@ -0,0 +1,28 @@
+package id3v2
+
+import (
+	"testing"
+)
+
+func TestParseFileType(t *testing.T) {
+	cases := map[string]FileType{
+		"\x00MPG":     FileTypeMPEG,
+		"\x00MPG/3":   FileTypeMPEG3,
+		"\x00MPG/2.5": FileTypeMPEG25,
+		"\x00MPG/AAC": FileTypeMPEGAAC,
+		"\x00PCM\x00": FileTypePCM,
+	}
+	for data, expected := range cases {
+		ft, err := ParseFileType([]byte(data))
+		if err != nil {
+			t.Errorf("expected no error for %q but got %v", data, err)
+		}
+		if ft != expected {
+			t.Errorf("expected %q to be %s but got %s", data, expected, ft)
+		}
+	}
+
+	if _, err := ParseFileType([]byte("\x00OGG")); err == nil {
+		t.Error("expected an error for an unknown file type")
+	}
+}
@@ -0,0 +1,77 @@
+package id3v2
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A KV is a frame as a human-readable key and value, for display.
+type KV struct {
+	Key   string
+	Value string
+}
+
+// Flatten converts the frames of a tag in decoded order into key/value pairs.
+// Keys are taken from descriptions, a map of frame ID to the description of
+// the frame as found in the SupportedFrames map of each version, falling back
+// to the frame ID. Text, comment and URL frames are decoded, other frames are
+// shown as a placeholder giving their size.
+func Flatten(tag Tag, descriptions map[string]string) []KV {
+	var kvs []KV
+
+	tag.EachFrame(func(id string, data []byte) error {
+		key := id
+		if desc, ok := descriptions[id]; ok {
+			key = cleanDescription(desc)
+		}
+
+		kvs = append(kvs, KV{Key: key, Value: flattenValue(id, data)})
+		return nil
+	})
+
+	return kvs
+}
+
+// flattenValue returns the data of a frame as a string for display.
+func flattenValue(id string, data []byte) string {
+	var s string
+	var err error
+
+	switch {
+	case id == "TXXX":
+		var desc, value string
+		desc, value, err = ParseTXXX(data)
+		s = desc + ": " + value
+	case id == "WXXX":
+		var desc, url string
+		desc, url, err = ParseWXXX(data)
+		s = desc + ": " + url
+	case id == "COMM" || id == "USLT":
+		_, _, s, err = parseCOMM(data)
+	case strings.HasPrefix(id, "T"):
+		s, err = DecodeTextFrame(data)
+	case strings.HasPrefix(id, "W") && id != "WXXX":
+		s, err = ParseURLFrame(data)
+	default:
+		return fmt.Sprintf("<binary data, %d bytes>", len(data))
+	}
+
+	if err != nil {
+		return fmt.Sprintf("<invalid data, %d bytes>", len(data))
+	}
+	return s
+}
+
+// cleanDescription turns a description such as "[#TIT2 Title/songname]" or
+// "[[#sec4.20|Audio encryption]]" into plain text.
+func cleanDescription(desc string) string {
+	desc = strings.Trim(desc, "[]")
+
+	if i := strings.Index(desc, "|"); i >= 0 {
+		return desc[i+1:]
+	}
+	if i := strings.Index(desc, " "); i >= 0 && strings.HasPrefix(desc, "#") {
+		return desc[i+1:]
+	}
+	return desc
+}
@@ -1,7 +1,22 @@
 package id3v2
 
+import "strings"
+
 type FrameID string
 
+// FramesWithPrefix returns the frames of a tag whose ID starts with prefix,
+// such as "T" for text information frames or "W" for URL link frames. Use
+// FrameOrder to iterate the result in decoded order.
+func FramesWithPrefix(tag Tag, prefix string) map[string][]byte {
+	m := make(map[string][]byte)
+	for id, data := range tag.Frames() {
+		if strings.HasPrefix(id, prefix) {
+			m[id] = data
+		}
+	}
+	return m
+}
+
 var SupportedFrameIDMap = map[FrameID]bool{
 	"AENC": true, // [[#sec4.20|Audio encryption]]
 	"APIC": true, // [#sec4.15 Attached picture]
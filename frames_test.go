@@ -0,0 +1,54 @@
+package id3v2_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/jlubawy/go-id3v2"
+)
+
+func TestFramesWithPrefix(t *testing.T) {
+	tag := buildTag(t,
+		"WOAR", []byte("http://example.com/artist"),
+		"TPE1", []byte("\x00Artist"),
+		"APIC", []byte("\x00image/png\x00\x03\x00\x89PNG"),
+		"TIT2", []byte("\x00Title"),
+		"TXXX", []byte("\x00key\x00value"),
+		"TPE1", []byte("\x00Second Artist"),
+	)
+
+	text := id3v2.FramesWithPrefix(tag, "T")
+	if len(text) != 3 {
+		t.Fatalf("expected 3 text frames but got %d", len(text))
+	}
+	if !bytes.Equal(text["TPE1"], []byte("\x00Artist")) {
+		t.Errorf("expected the first TPE1 frame but got %q", text["TPE1"])
+	}
+	if _, ok := text["WOAR"]; ok {
+		t.Error("expected WOAR not to match prefix T")
+	}
+
+	// FrameOrder gives the matches in decoded order
+	var order []string
+	for _, id := range tag.FrameOrder() {
+		if _, ok := text[id]; ok {
+			order = append(order, id)
+		}
+	}
+	expected := []string{"TPE1", "TIT2", "TXXX", "TPE1"}
+	if len(order) != len(expected) {
+		t.Fatalf("expected order %v but got %v", expected, order)
+	}
+	for i := range expected {
+		if order[i] != expected[i] {
+			t.Fatalf("expected order %v but got %v", expected, order)
+		}
+	}
+
+	if m := id3v2.FramesWithPrefix(tag, "TP"); len(m) != 1 {
+		t.Errorf("expected only TPE1 to match prefix TP but got %d frames", len(m))
+	}
+	if m := id3v2.FramesWithPrefix(tag, "X"); len(m) != 0 {
+		t.Errorf("expected no frames to match prefix X but got %d", len(m))
+	}
+}
@@ -0,0 +1,24 @@
+package id3v2
+
+import (
+	"testing"
+)
+
+func TestGenreToV1Index(t *testing.T) {
+	if n := len(V1Genres); n != 148 {
+		t.Errorf("expected 148 genres but got %d", n)
+	}
+
+	cases := map[string]int{
+		"Blues":     0,
+		"rock":      17,
+		"Hard Rock": 79,
+		"Synthpop":  147,
+		"Vaporwave": -1,
+	}
+	for name, expected := range cases {
+		if i := GenreToV1Index(name); i != expected {
+			t.Errorf("expected GenreToV1Index(%q) to equal %d but got %d", name, expected, i)
+		}
+	}
+}
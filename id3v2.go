@@ -3,36 +3,157 @@ package id3v2
 import (
 	"bufio"
 	"bytes"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
+	"reflect"
 )
 
 var ErrFormat = errors.New("id3v2: unknown format")
 var ErrVersion = errors.New("id3v2: unknown version")
 
+// ErrNoVersionsRegistered is returned in place of ErrVersion when no version
+// is registered at all, usually because no version package was imported.
+var ErrNoVersionsRegistered = errors.New("id3v2: no versions registered, import a version package such as github.com/jlubawy/go-id3v2/id3v2all")
+
 var FileIdentifier = []byte("ID3")
 
-// A version defines an ID3v2 version and how to decode it.
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// A version defines an ID3v2 version, how to decode and encode it and how to
+// create an empty tag of that version.
 type version struct {
 	major, revision byte
 	decode          func(io.Reader) (Tag, error)
+	encode          func(io.Writer, Tag) error
+	newTag          func() Tag
 }
 
 // Versions is the list of registered versions.
 var versions []version
 
+// A VersionInfo describes an ID3v2 version to RegisterVersionInfo. Every
+// field is required.
+type VersionInfo struct {
+	Major, Revision byte
+
+	// Decode decodes a tag of the version, starting at its header.
+	Decode func(io.Reader) (Tag, error)
+
+	// Encode encodes a tag in the version, including its header.
+	Encode func(io.Writer, Tag) error
+
+	// NewTag returns an empty tag of the version.
+	NewTag func() Tag
+}
+
+// RegisterVersion registers a version which can only be decoded. Encoding its
+// tags or creating new ones returns an error, register it with
+// RegisterVersionInfo for those.
 func RegisterVersion(major, revision byte, decode func(io.Reader) (Tag, error)) {
-	versions = append(versions, version{major, revision, decode})
+	versions = append(versions, version{major: major, revision: revision, decode: decode})
+}
+
+// RegisterVersionInfo makes a version available to Decode and the other
+// functions of this package, as done by the init function of each version
+// package. It panics if any function of info is nil.
+func RegisterVersionInfo(info VersionInfo) {
+	if info.Decode == nil || info.Encode == nil || info.NewTag == nil {
+		panic(fmt.Sprintf("id3v2: RegisterVersionInfo of version %d.%d is missing a function", info.Major, info.Revision))
+	}
+	versions = append(versions, version{info.Major, info.Revision, info.Decode, info.Encode, info.NewTag})
+}
+
+// lookupVersion returns the registered version with the given version string
+// (e.g. "id3v2.3.0").
+func lookupVersion(v string) (version, bool) {
+	for _, ver := range versions {
+		if v == fmt.Sprintf("id3v2.%d.%d", ver.major, ver.revision) {
+			return ver, true
+		}
+	}
+	return version{}, false
+}
+
+// lookupEncoder returns the registered version with the given version string
+// like lookupVersion, or an error if it isn't registered or can only be
+// decoded.
+func lookupEncoder(v string) (version, error) {
+	ver, ok := lookupVersion(v)
+	if !ok {
+		return version{}, errVersion()
+	}
+	if !ver.canEncode() {
+		return version{}, fmt.Errorf("id3v2: version %s was registered without an encoder", v)
+	}
+	return ver, nil
+}
+
+// canEncode returns true if the version was registered with an encoder and a
+// way to create new tags.
+func (ver version) canEncode() bool {
+	return ver.encode != nil && ver.newTag != nil
+}
+
+// errVersion returns the error for a version that isn't registered,
+// ErrNoVersionsRegistered if there are none or ErrVersion otherwise.
+func errVersion() error {
+	if len(versions) == 0 {
+		return ErrNoVersionsRegistered
+	}
+	return ErrVersion
+}
+
+// versionOf returns the registered version whose tags have the same type as
+// tag.
+func versionOf(tag Tag) (version, bool) {
+	for _, ver := range versions {
+		if ver.canEncode() && reflect.TypeOf(ver.newTag()) == reflect.TypeOf(tag) {
+			return ver, true
+		}
+	}
+	return version{}, false
+}
+
+// A Frame is a single frame of a tag along with the flags and extra header
+// bytes it's encoded with, in the layout of the tag's version.
+type Frame struct {
+	ID    string
+	Flags uint16
+	Data  []byte
+
+	// ExtraHeader holds the bytes the frame flags add to the frame header,
+	// such as the decompressed size of a compressed frame.
+	ExtraHeader []byte
+
+	// Raw marks unsupported frames kept as they were decoded, which are
+	// passed through verbatim on encode.
+	Raw bool
 }
 
 type Tag interface {
-	//Flags() byte
-	//Size() uint32
+	Version() (major, revision byte)
+	Flags() byte
 	Frames() map[string][]byte
+	FramesByID(id string) [][]byte
 	FrameOrder() []string
+	CompactFrameOrder()
+	EachFrame(func(id string, data []byte) error) error
+	FrameAt(i int) Frame
+	SetFrameAt(i int, id string, data []byte)
+	RemoveFrameAt(i int)
+	AppendFrame(f Frame)
+	GetFrame(id string) ([]byte, bool)
+	GetFrameFold(id string) ([]byte, bool)
+	FrameFlags(id string) uint16
+	SetFrameFlags(id string, flags uint16)
+	Flatten() []KV
+	SetFrame(id string, data []byte)
+	AddFrame(id string, data []byte)
 	SetFrames(map[string][]byte)
 	Size() uint32
+	Padding() uint32
 }
 
 func Decode(r io.Reader) (Tag, string, error) {
@@ -40,6 +161,12 @@ func Decode(r io.Reader) (Tag, string, error) {
 	var version [2]byte
 
 	br := bufio.NewReader(r)
+
+	// Skip a UTF-8 BOM that some tools write before the tag
+	if b, err := br.Peek(len(utf8BOM)); err == nil && bytes.Equal(b, utf8BOM) {
+		br.Discard(len(utf8BOM))
+	}
+
 	b, err := br.Peek(len(id) + len(version))
 	if err != nil {
 		return nil, "", ErrFormat
@@ -59,7 +186,106 @@ func Decode(r io.Reader) (Tag, string, error) {
 		}
 	}
 
-	return nil, fmt.Sprintf("id3v2.%d.%d", version[0], version[1]), ErrVersion
+	return nil, fmt.Sprintf("id3v2.%d.%d", version[0], version[1]), errVersion()
+}
+
+// The size of the header, and of the ID3v2.4 footer which mirrors it.
+const headerSize = 10
+
+// Bit 4 in the ID3v2.4 header flags indicates that a footer follows the tag.
+const headerFlagFooterPresent = byte(1 << 4)
+
+// Header flags with the same meaning in ID3v2.3.0 and ID3v2.4.0. ID3v2.2.0
+// only shares unsynchronisation, bit 6 indicates compression instead.
+const (
+	headerFlagUnsynchronisation = byte(1 << 7)
+	headerFlagExtendedHeader    = byte(1 << 6)
+	headerFlagExperimental      = byte(1 << 5)
+)
+
+// IsUnsynchronised returns true if the header flags of the decoded tag say
+// unsynchronisation was applied to it.
+func IsUnsynchronised(tag Tag) bool {
+	return tag.Flags()&headerFlagUnsynchronisation != 0
+}
+
+// HasExtendedHeader returns true if the header flags of the decoded tag say
+// an extended header follows the header.
+func HasExtendedHeader(tag Tag) bool {
+	return !isV22(tag) && tag.Flags()&headerFlagExtendedHeader != 0
+}
+
+// IsExperimental returns true if the header flags of the decoded tag mark it
+// as experimental.
+func IsExperimental(tag Tag) bool {
+	return !isV22(tag) && tag.Flags()&headerFlagExperimental != 0
+}
+
+// isV22 returns true if tag is of a version before ID3v2.3.0.
+func isV22(tag Tag) bool {
+	major, _ := tag.Version()
+	return major < 3
+}
+
+// tagSize returns the total size of a tag from its header, including the
+// header itself and the footer if there is one.
+func tagSize(h []byte) uint32 {
+	size := headerSize + SynchSafeToSize(binary.BigEndian.Uint32(h[6:10]))
+	if h[3] >= 4 && h[5]&headerFlagFooterPresent != 0 {
+		size += headerSize
+	}
+	return size
+}
+
+// TagSize returns the total size of a tag given just its 10 byte header,
+// including the header itself and the footer if there is one. It returns false
+// if the header isn't a valid ID3v2 header.
+func TagSize(header [headerSize]byte) (uint32, bool) {
+	if !isHeader(header[:]) {
+		return 0, false
+	}
+	return tagSize(header[:]), true
+}
+
+// AudioOffset returns the offset at which audio begins in rs, by reading the
+// header of the tag at the current position and skipping over the rest of the
+// tag without decoding it. A UTF-8 BOM before the tag is skipped along with
+// it, like Decode does. If there's no tag the current position is returned.
+// In both cases rs is left positioned at the returned offset.
+func AudioOffset(rs io.ReadSeeker) (int64, error) {
+	start, err := rs.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, err
+	}
+
+	b := make([]byte, len(utf8BOM)+headerSize)
+	n, _ := io.ReadFull(rs, b)
+	h, bom := trimBOM(b[:n])
+	if len(h) < headerSize || !bytes.Equal(h[0:3], FileIdentifier) {
+		return rs.Seek(start, io.SeekStart)
+	}
+
+	return rs.Seek(start+bom+int64(tagSize(h)), io.SeekStart)
+}
+
+// trimBOM returns b without a leading UTF-8 BOM, along with the number of
+// bytes trimmed.
+func trimBOM(b []byte) ([]byte, int64) {
+	if bytes.HasPrefix(b, utf8BOM) {
+		return b[len(utf8BOM):], int64(len(utf8BOM))
+	}
+	return b, 0
+}
+
+// NewTag returns an empty tag of the given version (e.g. "id3v2.3.0") which
+// frames can be added to before it's encoded.
+func NewTag(v string) (Tag, error) {
+	ver, err := lookupEncoder(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return ver.newTag(), nil
 }
 
 // SizeToSynchSafe converts a normal 28-bit size to a synchsafe format.
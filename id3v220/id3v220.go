@@ -0,0 +1,339 @@
+// Implements ID3v2.2.0 described at http://id3.org/id3v2-00
+
+package id3v220
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/jlubawy/go-id3v2"
+	"github.com/jlubawy/go-id3v2/internal/framelist"
+)
+
+const VersionString = "id3v2.2.0"
+
+// a - Unsynchronisation
+// Bit 7 in the 'ID3 flags' indicates whether or not unsynchronisation is used; a set bit indicates usage.
+// b - Compression
+// Bit 6 is indicating whether or not compression is used; a set bit indicates usage. Since no compression scheme has been decided yet, the ID3 decoder (for now) should just ignore the entire tag if the compression bit is set.
+const (
+	HeaderFlagCompression       = uint8(1 << 6)
+	HeaderFlagUnsynchronisation = uint8(1 << 7)
+)
+
+// ID3/file identifier      "ID3"
+// ID3 version              $02 00
+// ID3 flags                %xx000000
+// ID3 size             4 * %0xxxxxxx
+type header struct {
+	ID        [3]byte
+	Version   [2]byte
+	Flags     byte
+	SynchSafe uint32
+}
+
+// Frame ID       $xx xx xx (three characters)
+// Size           $xx xx xx
+type frame struct {
+	ID   [3]byte
+	Size [3]byte
+}
+
+// size returns the frame size as a number.
+func (f frame) size() uint32 {
+	return uint32(f.Size[0])<<16 | uint32(f.Size[1])<<8 | uint32(f.Size[2])
+}
+
+// setSize sets the frame size, which must fit in 24 bits.
+func (f *frame) setSize(s uint32) {
+	f.Size = [3]byte{byte(s >> 16), byte(s >> 8), byte(s)}
+}
+
+// The largest frame size that fits in the three byte size field.
+const maxFrameSize = 1<<24 - 1
+
+// The largest tag size a synchsafe integer can hold.
+const maxTagSize = 0x0FFFFFFF
+
+type tag struct {
+	header
+	framelist.List
+}
+
+// format describes the frame headers of ID3v2.2.0.
+var format = framelist.Format{
+	HeaderSize: binary.Size(frame{}),
+}
+
+// FrameFlags always returns 0, ID3v2.2.0 frames have no flags.
+func (t *tag) FrameFlags(id string) uint16 {
+	return 0
+}
+
+// SetFrameFlags does nothing, ID3v2.2.0 frames have no flags.
+func (t *tag) SetFrameFlags(id string, flags uint16) {}
+
+// Flatten returns the frames as human-readable key/value pairs.
+func (t *tag) Flatten() []id3v2.KV {
+	return id3v2.Flatten(t, SupportedFrames)
+}
+
+// Size returns the size of the tag as decoded, including the header and any
+// padding, or once the frames change the size they take up encoded with the
+// header.
+func (t *tag) Size() uint32 {
+	if t.Changed() {
+		return uint32(binary.Size(t.header)) + t.FramesSize()
+	}
+	return id3v2.SynchSafeToSize(t.SynchSafe) + uint32(binary.Size(t.header))
+}
+
+// Version returns the major version and revision of the tag, e.g. 3 and 0
+// for ID3v2.3.0.
+func (t *tag) Version() (major, revision byte) {
+	return t.header.Version[0], t.header.Version[1]
+}
+
+// Flags returns the header flags of the tag as decoded.
+func (t *tag) Flags() byte {
+	return t.header.Flags
+}
+
+// NewTag returns an empty ID3v2.2.0 tag.
+func NewTag() id3v2.Tag {
+	t := &tag{
+		header: header{
+			Version: [2]byte{2, 0},
+		},
+		List: framelist.New(format, nil, 0),
+	}
+	copy(t.header.ID[:], id3v2.FileIdentifier)
+
+	return id3v2.Tag(t)
+}
+
+func Decode(r io.Reader) (id3v2.Tag, error) {
+	t := &tag{}
+
+	if err := binary.Read(r, binary.BigEndian, &t.header); err != nil {
+		return nil, err
+	}
+
+	if !bytes.Equal(t.header.ID[:], id3v2.FileIdentifier) {
+		return nil, id3v2.ErrFormat
+	}
+
+	if t.header.Flags&HeaderFlagCompression != 0 {
+		return nil, fmt.Errorf("id3v220: compressed tags are not supported")
+	}
+
+	bytesLeft := id3v2.SynchSafeToSize(t.header.SynchSafe)
+
+	if t.header.Flags&HeaderFlagUnsynchronisation != 0 {
+		body, err := ioutil.ReadAll(id3v2.NewUnsyncReader(io.LimitReader(r, int64(bytesLeft))))
+		if err != nil {
+			return nil, err
+		}
+
+		r = bytes.NewReader(body)
+		bytesLeft = uint32(len(body))
+	}
+
+	// Every frame consumes at least its header so a tag can't hold more
+	// frames than this, cap the loop in case the arithmetic ever goes wrong
+	maxFrames := bytesLeft/uint32(binary.Size(frame{})) + 1
+
+	var frames []id3v2.Frame
+	var padding uint32
+
+	for n := uint32(0); bytesLeft > 0; n++ {
+		f := frame{}
+
+		if n >= maxFrames {
+			return nil, fmt.Errorf("id3v220: expected at most %d frames in the tag", maxFrames)
+		}
+
+		// Anything too small to hold a frame header can only be padding
+		if bytesLeft < uint32(binary.Size(f)) {
+			padding = bytesLeft
+			break
+		}
+
+		if err := binary.Read(r, binary.BigEndian, &f); err != nil {
+			return nil, err
+		}
+
+		bytesLeft = bytesLeft - uint32(binary.Size(f))
+
+		if f.ID[0] == 0 {
+			padding = bytesLeft + uint32(binary.Size(f))
+			break
+		}
+
+		// Refuse frames that claim more data than the tag holds before
+		// allocating anything for them
+		if f.size() > bytesLeft {
+			return nil, fmt.Errorf("id3v220: frame '%s' size %d exceeds the %d bytes left in the tag", f.ID[:], f.size(), bytesLeft)
+		}
+
+		data := make([]byte, f.size())
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, err
+		}
+
+		bytesLeft = bytesLeft - f.size()
+
+		fe := id3v2.Frame{
+			ID:   string(f.ID[:]),
+			Data: data,
+		}
+
+		if _, ok := SupportedFrames[fe.ID]; !ok {
+			fe.Raw = true
+		}
+
+		frames = append(frames, fe)
+	}
+
+	t.List = framelist.New(format, frames, padding)
+
+	return id3v2.Tag(t), nil
+}
+
+// frameEntries returns every frame of a tag in order.
+func frameEntries(tg id3v2.Tag) []id3v2.Frame {
+	if t, ok := tg.(*tag); ok {
+		return t.Entries()
+	}
+	return framelist.Of(tg)
+}
+
+func Encode(w io.Writer, tag id3v2.Tag) error {
+	fBuf := &bytes.Buffer{}
+
+	for _, fe := range frameEntries(tag) {
+		if len(fe.ID) != 3 {
+			return fmt.Errorf("id3v220: expected frame ID of length 3 but got %d", len(fe.ID))
+		}
+		if _, ok := SupportedFrames[fe.ID]; !ok && !fe.Raw {
+			return fmt.Errorf("id3v220: unsupported frame ID '%s'", fe.ID)
+		}
+		if len(fe.Data) > maxFrameSize {
+			return fmt.Errorf("id3v220: frame '%s' size %d exceeds the maximum of %d", fe.ID, len(fe.Data), maxFrameSize)
+		}
+
+		f := frame{}
+		copy(f.ID[:], []byte(fe.ID))
+		f.setSize(uint32(len(fe.Data)))
+
+		if err := binary.Write(fBuf, binary.BigEndian, f); err != nil {
+			return err
+		}
+
+		if _, err := fBuf.Write(fe.Data); err != nil {
+			return err
+		}
+	}
+
+	if fBuf.Len() > maxTagSize {
+		return fmt.Errorf("id3v220: tag size %d exceeds the maximum of %d", fBuf.Len(), maxTagSize)
+	}
+
+	h := header{
+		Version: [2]byte{2, 0},
+		Flags:   0,
+	}
+	copy(h.ID[:], id3v2.FileIdentifier)
+
+	h.SynchSafe = id3v2.SizeToSynchSafe(uint32(fBuf.Len()))
+
+	if err := binary.Write(w, binary.BigEndian, h); err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(w, fBuf); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
+
+func init() {
+	id3v2.RegisterVersionInfo(id3v2.VersionInfo{
+		Major:    2,
+		Revision: 0,
+		Decode:   Decode,
+		Encode:   Encode,
+		NewTag:   NewTag,
+	})
+}
+
+// SupportedFrames is a map of frames supported by ID3v2.2.0 and their descriptions.
+var SupportedFrames = map[string]string{
+	"BUF": "[#sec4.19 Recommended buffer size]",
+	"CNT": "[#sec4.17 Play counter]",
+	"COM": "[#sec4.11 Comments]",
+	"CRA": "[#sec4.21 Audio encryption]",
+	"CRM": "[#sec4.20 Encrypted meta frame]",
+	"ETC": "[#sec4.6 Event timing codes]",
+	"EQU": "[#sec4.13 Equalization]",
+	"GEO": "[#sec4.16 General encapsulated object]",
+	"IPL": "[#sec4.4 Involved people list]",
+	"LNK": "[#sec4.22 Linked information]",
+	"MCI": "[#sec4.5 Music CD Identifier]",
+	"MLL": "[#sec4.7 MPEG location lookup table]",
+	"PIC": "[#sec4.15 Attached picture]",
+	"POP": "[#sec4.18 Popularimeter]",
+	"REV": "[#sec4.14 Reverb]",
+	"RVA": "[#sec4.12 Relative volume adjustment]",
+	"SLT": "[#sec4.10 Synchronized lyric/text]",
+	"STC": "[#sec4.8 Synced tempo codes]",
+	"TAL": "[#TAL Album/Movie/Show title]",
+	"TBP": "[#TBP BPM (Beats Per Minute)]",
+	"TCM": "[#TCM Composer]",
+	"TCO": "[#TCO Content type]",
+	"TCR": "[#TCR Copyright message]",
+	"TDA": "[#TDA Date]",
+	"TDY": "[#TDY Playlist delay]",
+	"TEN": "[#TEN Encoded by]",
+	"TFT": "[#TFT File type]",
+	"TIM": "[#TIM Time]",
+	"TKE": "[#TKE Initial key]",
+	"TLA": "[#TLA Language(s)]",
+	"TLE": "[#TLE Length]",
+	"TMT": "[#TMT Media type]",
+	"TOA": "[#TOA Original artist(s)/performer(s)]",
+	"TOF": "[#TOF Original filename]",
+	"TOL": "[#TOL Original Lyricist(s)/text writer(s)]",
+	"TOR": "[#TOR Original release year]",
+	"TOT": "[#TOT Original album/Movie/Show title]",
+	"TP1": "[#TP1 Lead artist(s)/Lead performer(s)/Soloist(s)/Performing group]",
+	"TP2": "[#TP2 Band/Orchestra/Accompaniment]",
+	"TP3": "[#TP3 Conductor/Performer refinement]",
+	"TP4": "[#TP4 Interpreted, remixed, or otherwise modified by]",
+	"TPA": "[#TPA Part of a set]",
+	"TPB": "[#TPB Publisher]",
+	"TRC": "[#TRC ISRC (International Standard Recording Code)]",
+	"TRD": "[#TRD Recording dates]",
+	"TRK": "[#TRK Track number/Position in set]",
+	"TSI": "[#TSI Size]",
+	"TSS": "[#TSS Software/hardware and settings used for encoding]",
+	"TT1": "[#TT1 Content group description]",
+	"TT2": "[#TT2 Title/Songname/Content description]",
+	"TT3": "[#TT3 Subtitle/Description refinement]",
+	"TXT": "[#TXT Lyricist/text writer]",
+	"TXX": "[#TXX User defined text information frame]",
+	"TYE": "[#TYE Year]",
+	"UFI": "[#sec4.1 Unique file identifier]",
+	"ULT": "[#sec4.9 Unsychronized lyric/text transcription]",
+	"WAF": "[#WAF Official audio file webpage]",
+	"WAR": "[#WAR Official artist/performer webpage]",
+	"WAS": "[#WAS Official audio source webpage]",
+	"WCM": "[#WCM Commercial information]",
+	"WCP": "[#WCP Copyright/Legal information]",
+	"WPB": "[#WPB Publishers official webpage]",
+	"WXX": "[#WXX User defined URL link frame]",
+}
@@ -0,0 +1,93 @@
+package id3v220
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/jlubawy/go-id3v2"
+)
+
+var testTag = []byte{
+	'I', 'D', '3', 2, 0, 0, 0, 0, 0, 24,
+	'T', 'T', '2', 0, 0, 5,
+	0, 'T', 'e', 's', 't',
+	'T', 'P', '1', 0, 0, 7,
+	0, 'A', 'r', 't', 'i', 's', 't',
+}
+
+func TestDecode(t *testing.T) {
+	tg, ver, err := id3v2.Decode(bytes.NewReader(testTag))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ver != VersionString {
+		t.Errorf("expected version %s but got %s", VersionString, ver)
+	}
+
+	frames := tg.Frames()
+	if len(frames) != 2 {
+		t.Fatalf("expected 2 frames but got %d", len(frames))
+	}
+	if s := string(frames["TT2"]); s != "\x00Test" {
+		t.Errorf("expected TT2 '\\x00Test' but got %q", s)
+	}
+	if s := string(frames["TP1"]); s != "\x00Artist" {
+		t.Errorf("expected TP1 '\\x00Artist' but got %q", s)
+	}
+	if order := tg.FrameOrder(); order[0] != "TT2" || order[1] != "TP1" {
+		t.Errorf("expected frame order [TT2 TP1] but got %v", order)
+	}
+	if major, revision := tg.Version(); major != 2 || revision != 0 {
+		t.Errorf("expected version 2.0 but got %d.%d", major, revision)
+	}
+}
+
+func TestDecodePadding(t *testing.T) {
+	b := append([]byte{}, testTag...)
+	b[9] += 8
+	b = append(b, make([]byte, 8)...)
+
+	tg, err := Decode(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := tg.Padding(); p != 8 {
+		t.Errorf("expected 8 bytes of padding but got %d", p)
+	}
+}
+
+func TestDecodeFrameSizeExceedsTag(t *testing.T) {
+	b := append([]byte{}, testTag...)
+	b[15] = 6 // TT2 size one byte larger than the tag
+
+	b = b[:21]
+	b[9] = 11
+	if _, err := Decode(bytes.NewReader(b)); err == nil {
+		t.Error("expected an error for a frame larger than the tag")
+	}
+}
+
+func TestEncode(t *testing.T) {
+	tg, err := Decode(bytes.NewReader(testTag))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := Encode(buf, tg); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), testTag) {
+		t.Errorf("expected %v but got %v", testTag, buf.Bytes())
+	}
+
+	if err := Encode(buf, NewTag()); err != nil {
+		t.Fatal(err)
+	}
+
+	tg = NewTag()
+	tg.SetFrame("TIT2", []byte("\x00Test"))
+	if err := Encode(buf, tg); err == nil {
+		t.Error("expected an error for a four character frame ID")
+	}
+}
@@ -0,0 +1,133 @@
+package id3v230
+
+import (
+	"strings"
+
+	"github.com/jlubawy/go-id3v2"
+)
+
+// APIC is an attached picture frame, as parsed by id3v2.ParseAPIC.
+type APIC = id3v2.APIC
+
+// DecodeAPIC decodes the data of an APIC frame. A UTF-16 description ends
+// with a double null.
+func DecodeAPIC(data []byte) (*APIC, error) {
+	pic, err := id3v2.ParseAPIC(data)
+	if err != nil {
+		return nil, err
+	}
+	return &pic, nil
+}
+
+// EncodeAPIC encodes a picture as the data of an APIC frame. The MIME type
+// must not be empty and the picture type must be at most $14.
+func EncodeAPIC(pic APIC) ([]byte, error) {
+	return id3v2.EncodeAPIC(pic)
+}
+
+// Comment is a comment frame, as parsed by id3v2.ParseCOMM.
+type Comment = id3v2.Comment
+
+// DecodeCOMM decodes the data of a COMM frame. A UTF-16 description ends with
+// a double null.
+func DecodeCOMM(data []byte) (*Comment, error) {
+	c, err := id3v2.ParseCOMM(data)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Lyrics is an unsynchronised lyrics frame, as parsed by id3v2.ParseUSLT.
+type Lyrics = id3v2.Lyrics
+
+// DecodeUSLT decodes the data of a USLT frame.
+func DecodeUSLT(data []byte) (*Lyrics, error) {
+	l, err := id3v2.ParseUSLT(data)
+	if err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+// EncodeUSLT encodes lyrics as the data of a USLT frame, the description
+// ending with a null terminator as wide as the encoding calls for.
+func EncodeUSLT(l Lyrics) ([]byte, error) {
+	return id3v2.EncodeUSLT(l)
+}
+
+// DecodeTXXX decodes the data of a TXXX frame into its description and value.
+// A tag may hold many TXXX frames, see id3v2.Tag.FramesByID.
+func DecodeTXXX(data []byte) (desc, value string, err error) {
+	return id3v2.ParseTXXX(data)
+}
+
+// EncodeTXXX encodes a description and value as the data of a TXXX frame.
+func EncodeTXXX(desc, value string, enc id3v2.Encoding) ([]byte, error) {
+	return id3v2.EncodeTXXX(desc, value, enc)
+}
+
+// DecodeWXXX decodes the data of a WXXX frame into its description and URL.
+// The URL is always read as ISO-8859-1 whatever the encoding byte says.
+func DecodeWXXX(data []byte) (desc, url string, err error) {
+	return id3v2.ParseWXXX(data)
+}
+
+// EncodeWXXX encodes a description and URL as the data of a WXXX frame.
+func EncodeWXXX(desc, url string, enc id3v2.Encoding) ([]byte, error) {
+	return id3v2.EncodeWXXX(desc, url, enc)
+}
+
+// DecodeURLFrame decodes the data of a URL link frame such as WOAR, which is
+// an ISO-8859-1 URL without an encoding byte, up to the first null.
+func DecodeURLFrame(data []byte) string {
+	s, _ := id3v2.ParseURLFrame(data)
+	return s
+}
+
+// IsURLFrame returns true if id is a supported URL link frame, that is one
+// starting with 'W' other than the user defined WXXX.
+func IsURLFrame(id string) bool {
+	_, ok := SupportedFrames[id]
+	return ok && strings.HasPrefix(id, "W") && id != "WXXX"
+}
+
+// Popularimeter is a popularimeter frame, as parsed by id3v2.ParsePOPM.
+type Popularimeter = id3v2.Popularimeter
+
+// DecodePOPM decodes the data of a POPM frame. A missing counter is 0.
+func DecodePOPM(data []byte) (*Popularimeter, error) {
+	p, err := id3v2.ParsePOPM(data)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// EncodePOPM encodes a popularimeter as the data of a POPM frame.
+func EncodePOPM(p Popularimeter) ([]byte, error) {
+	return id3v2.EncodePOPM(p)
+}
+
+// DecodePCNT decodes the data of a PCNT frame, a play counter which may grow
+// beyond 4 bytes. Counters too large for a uint64 are an error.
+func DecodePCNT(data []byte) (uint64, error) {
+	return id3v2.ParsePCNT(data)
+}
+
+// EncodePCNT encodes a play count as the data of a PCNT frame.
+func EncodePCNT(count uint64) []byte {
+	return id3v2.EncodePCNT(count)
+}
+
+// DecodeUFID decodes the data of a UFID frame into its owner identifier and
+// binary identifier.
+func DecodeUFID(data []byte) (owner string, id []byte, err error) {
+	return id3v2.ParseUFID(data)
+}
+
+// EncodeUFID encodes an owner identifier and an identifier of at most 64
+// bytes as the data of a UFID frame.
+func EncodeUFID(owner string, id []byte) ([]byte, error) {
+	return id3v2.EncodeUFID(owner, id)
+}
@@ -3,16 +3,27 @@
 package id3v230
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/binary"
+	"errors"
 	"fmt"
+	"hash"
+	"hash/crc32"
 	"io"
+	"io/ioutil"
+	"math"
 
 	"github.com/jlubawy/go-id3v2"
+	"github.com/jlubawy/go-id3v2/internal/framelist"
 )
 
 const VersionString = "id3v2.3.0"
 
+// ErrTruncated is returned, wrapped with the frame being read, when the input
+// ends before the size given by the tag header.
+var ErrTruncated = errors.New("id3v230: tag is truncated")
+
 // a - Unsynchronisation
 // Bit 7 in the 'ID3v2 flags' indicates whether or not unsynchronisation is used (see section 5 for details); a set bit indicates usage.
 // b - Extended header
@@ -49,9 +60,24 @@ type extendedHeader struct {
 	PaddingSize uint32
 }
 
+// a - Tag alter preservation
+// b - File alter preservation
+// c - Read only
+// i - Compression
+// j - Encryption
+// k - Grouping identity
+const (
+	FrameFlagGroupingIdentity      = uint16(1 << 5)
+	FrameFlagEncryption            = uint16(1 << 6)
+	FrameFlagCompression           = uint16(1 << 7)
+	FrameFlagReadOnly              = uint16(1 << 13)
+	FrameFlagFileAlterPreservation = uint16(1 << 14)
+	FrameFlagTagAlterPreservation  = uint16(1 << 15)
+)
+
 // Frame ID       $xx xx xx xx (four characters)
 // Size           $xx xx xx xx
-// Flags          $xx xx
+// Flags          $xx xx (%abc00000 %ijk00000)
 type frame struct {
 	ID    [4]byte
 	Size  uint32
@@ -61,83 +87,366 @@ type frame struct {
 type tag struct {
 	header
 	extendedHeader
+	framelist.List
 
-	frames     map[string][]byte
-	frameOrder []string
+	crc32 uint32
+
+	// plainSize is set if the tag size was read as a plain integer
+	plainSize bool
+
+	// synchSafeFrames holds the ID of each frame whose size was read as
+	// synchsafe and differs from the plain reading
+	synchSafeFrames []string
 }
 
-func (t *tag) Frames() map[string][]byte {
-	return t.frames
+// format describes the frame headers of ID3v2.3.0.
+var format = framelist.Format{
+	HeaderSize:      binary.Size(frame{}),
+	StatusFlags:     FrameFlagTagAlterPreservation | FrameFlagFileAlterPreservation | FrameFlagReadOnly,
+	ExtraHeaderSize: extraHeaderSize,
 }
 
-func (t *tag) FrameOrder() []string {
-	return t.frameOrder
+// Flatten returns the frames as human-readable key/value pairs.
+func (t *tag) Flatten() []id3v2.KV {
+	return id3v2.Flatten(t, SupportedFrames)
 }
 
-func (t *tag) SetFrames(f map[string][]byte) {
-	t.frames = f
+// Size returns the size of the tag as decoded, including the header and any
+// padding, or once the frames change the size they take up encoded with the
+// header.
+func (t *tag) Size() uint32 {
+	if t.Changed() {
+		return uint32(binary.Size(t.header)) + t.FramesSize()
+	}
+	return id3v2.SynchSafeToSize(t.SynchSafe) + uint32(binary.Size(t.header))
+}
 
-	// Update the size
-	hdrSize := uint32(binary.Size(frame{}))
-	framesSize := uint32(0)
-	for _, data := range f {
-		framesSize = framesSize + hdrSize + 1 + uint32(binary.Size(data))
+// EncodeLosses describes what Encode doesn't keep of the tag as decoded,
+// besides padding.
+func (t *tag) EncodeLosses() []string {
+	var losses []string
+	if t.header.Flags&HeaderFlagUnsynchronisation != 0 {
+		losses = append(losses, "unsynchronisation is not preserved")
+	}
+	if t.extendedHeader.Flags&ExtendedHeaderFlagCRC32DataPresent != 0 {
+		losses = append(losses, "extended header CRC-32 is not preserved")
+	}
+	if t.header.Flags&HeaderFlagExperimentalIndicator != 0 {
+		losses = append(losses, "experimental indicator is not preserved")
+	}
+	return losses
+}
+
+// Version returns the major version and revision of the tag, e.g. 3 and 0
+// for ID3v2.3.0.
+func (t *tag) Version() (major, revision byte) {
+	return t.header.Version[0], t.header.Version[1]
+}
+
+// Flags returns the header flags of the tag as decoded.
+func (t *tag) Flags() byte {
+	return t.header.Flags
+}
+
+// NewTag returns an empty ID3v2.3.0 tag.
+func NewTag() id3v2.Tag {
+	t := &tag{
+		header: header{
+			Version: [2]byte{3, 0},
+		},
+		List: framelist.New(format, nil, 0),
 	}
+	copy(t.header.ID[:], id3v2.FileIdentifier)
 
-	t.header.SynchSafe = id3v2.SizeToSynchSafe(framesSize)
+	return id3v2.Tag(t)
 }
 
-func (t *tag) Size() uint32 {
-	return id3v2.SynchSafeToSize(t.SynchSafe) + uint32(binary.Size(t.header))
+// DecodeOptions control how tolerant DecodeWithOptions is of non-conforming
+// tags. The zero value decodes strictly, as Decode does.
+type DecodeOptions struct {
+	// RecoverFramesAfterPadding continues scanning the rest of the tag for
+	// another frame once padding is reached, for encoders that write frames
+	// after the padding.
+	RecoverFramesAfterPadding bool
+
+	// CheckPaddingSize returns an error if the padding size declared by the
+	// extended header doesn't match the padding that follows the frames.
+	CheckPaddingSize bool
+
+	// ReadFramesWithoutSize reads frames until padding, the first header
+	// that isn't a frame or the end of the stream when the header declares a
+	// size of zero but a frame follows, as written by some broken encoders.
+	// The CRC isn't verified for such tags.
+	ReadFramesWithoutSize bool
+
+	// FrameSizeEndianness is the byte order frame sizes are read in, for
+	// tools that wrongly write them little-endian. Defaults to big-endian.
+	FrameSizeEndianness binary.ByteOrder
+
+	// VerifyCRC returns an error if the extended header holds a CRC-32 which
+	// doesn't match the frames. The CRC is computed as the frames are read
+	// rather than buffering them.
+	VerifyCRC bool
+
+	// RepairTagSize reads the tag size as a plain big-endian integer, as
+	// written by tools that forget to make it synchsafe, when the frames
+	// don't fit the synchsafe size but do fit the plain one. Whether it was
+	// used is given by PlainTagSize. It can't be combined with
+	// ReadFramesWithoutSize.
+	RepairTagSize bool
+
+	// RepairFrameSizes reads frame sizes as synchsafe, as written by tools
+	// that use the ID3v2.4.0 encoding in ID3v2.3.0 tags, when the frames
+	// don't line up with plain sizes but do with synchsafe ones. The frames
+	// it was used for are given by SynchSafeFrames. It's ignored along with
+	// ReadFramesWithoutSize.
+	RepairFrameSizes bool
+
+	// MaxFrames returns an error once a tag holds more than this many frames
+	// when greater than zero, guarding against corrupt tags declaring
+	// thousands of tiny frames.
+	MaxFrames int
+
+	// ResyncFrames scans forward to the next frame ID within the tag when a
+	// frame header doesn't start with one, skipping the bytes in between.
+	// This rescues the frames following one whose data is longer than its
+	// declared size, as written by some broken tools.
+	ResyncFrames bool
 }
 
 func Decode(r io.Reader) (id3v2.Tag, error) {
+	return DecodeWithOptions(r, DecodeOptions{})
+}
+
+func DecodeWithOptions(r io.Reader, opts DecodeOptions) (id3v2.Tag, error) {
+	if opts.RepairTagSize && opts.ReadFramesWithoutSize {
+		return nil, fmt.Errorf("id3v230: RepairTagSize can't be combined with ReadFramesWithoutSize")
+	}
+
 	t := &tag{}
 
 	if err := binary.Read(r, binary.BigEndian, &t.header); err != nil {
 		return nil, err
 	}
 
+	if !bytes.Equal(t.header.ID[:], id3v2.FileIdentifier) {
+		return nil, id3v2.ErrFormat
+	}
+
 	bytesLeft := id3v2.SynchSafeToSize(t.header.SynchSafe)
 
-	// Read the extended header if one exists
+	unbounded := false
+	if bytesLeft == 0 && opts.ReadFramesWithoutSize {
+		var id [4]byte
+		n, _ := io.ReadFull(r, id[:])
+		r = io.MultiReader(bytes.NewReader(id[:n]), r)
+
+		if n == len(id) && isFrameID(id[:]) {
+			unbounded = true
+			bytesLeft = math.MaxUint32
+		}
+	}
+
+	if opts.RepairTagSize && t.header.SynchSafe != bytesLeft && t.header.SynchSafe <= maxTagSize {
+		plain := t.header.SynchSafe
+
+		// Buffer enough for either size and put it back for the frames,
+		// growing with what the stream holds rather than trusting the size
+		body, err := ioutil.ReadAll(io.LimitReader(r, int64(plain)))
+		if err != nil {
+			return nil, err
+		}
+		r = io.MultiReader(bytes.NewReader(body), r)
+
+		if !framesFit(body, bytesLeft) && framesFit(body, plain) {
+			bytesLeft = plain
+			t.header.SynchSafe = id3v2.SizeToSynchSafe(plain)
+			t.plainSize = true
+		}
+	}
+
+	// Everything after the header is unsynchronised, including the extended
+	// header, so undo it before reading anything else. Without a size the
+	// frames are undone as they're read rather than reading up to the end of
+	// the stream.
+	if t.header.Flags&HeaderFlagUnsynchronisation != 0 && unbounded {
+		r = id3v2.NewUnsyncReader(r)
+	} else if t.header.Flags&HeaderFlagUnsynchronisation != 0 {
+		body, err := ioutil.ReadAll(id3v2.NewUnsyncReader(io.LimitReader(r, int64(bytesLeft))))
+		if err != nil {
+			return nil, err
+		}
+
+		r = bytes.NewReader(body)
+		bytesLeft = uint32(len(body))
+	}
+
+	// Read the extended header if one exists, trusting its declared size
+	// rather than the size of the fields it's expected to hold since some
+	// writers add vendor-specific data or miscompute the CRC's size
 	if t.header.Flags&HeaderFlagExtendedHeader != 0 {
-		if err := binary.Read(r, binary.BigEndian, &t.extendedHeader); err != nil {
+		if err := binary.Read(r, binary.BigEndian, &t.extendedHeader.Size); err != nil {
 			return nil, err
 		}
 
-		bytesLeft = bytesLeft - uint32(binary.Size(t.extendedHeader))
+		sizeSize := uint32(binary.Size(t.extendedHeader.Size))
+		if bytesLeft < sizeSize || t.extendedHeader.Size > bytesLeft-sizeSize {
+			return nil, fmt.Errorf("id3v230: extended header size %d exceeds the %d bytes left in the tag", t.extendedHeader.Size, bytesLeft)
+		}
+
+		eh := make([]byte, t.extendedHeader.Size)
+		if _, err := io.ReadFull(r, eh); err != nil {
+			return nil, err
+		}
 
-		// Read the CRC-32 data if any exists
-		if t.extendedHeader.Flags&ExtendedHeaderFlagCRC32DataPresent != 0 {
-			var crc32 uint32
+		bytesLeft = bytesLeft - sizeSize - t.extendedHeader.Size
 
-			bytesLeft = bytesLeft - uint32(binary.Size(crc32))
+		if len(eh) >= 6 {
+			t.extendedHeader.Flags = binary.BigEndian.Uint16(eh[0:2])
+			t.extendedHeader.PaddingSize = binary.BigEndian.Uint32(eh[2:6])
+		}
 
-			_ = crc32
+		// Keep the CRC-32 data if any exists
+		if t.extendedHeader.Flags&ExtendedHeaderFlagCRC32DataPresent != 0 && len(eh) >= 10 {
+			t.crc32 = binary.BigEndian.Uint32(eh[6:10])
 		}
 	}
 
-	t.frames = make(map[string][]byte)
+	// The CRC covers the frames but not the padding that follows them
+	synchSafeSizes := false
+	if opts.RepairFrameSizes && !unbounded {
+		// Buffer the frames to check both sizes and put them back
+		body, err := ioutil.ReadAll(io.LimitReader(r, int64(bytesLeft)))
+		if err != nil {
+			return nil, err
+		}
+		r = io.MultiReader(bytes.NewReader(body), r)
+
+		synchSafeSizes = !frameSizesFit(body, binary.BigEndian.Uint32) && frameSizesFit(body, synchSafeFrameSize)
+	}
 
-	for bytesLeft > 0 {
+	var crc hash.Hash32
+	var crcFrames *io.LimitedReader
+	if opts.VerifyCRC && !unbounded && t.extendedHeader.Flags&ExtendedHeaderFlagCRC32DataPresent != 0 {
+		if t.extendedHeader.PaddingSize > bytesLeft {
+			return nil, fmt.Errorf("id3v230: extended header padding size %d exceeds the %d bytes left in the tag", t.extendedHeader.PaddingSize, bytesLeft)
+		}
+
+		crc = crc32.NewIEEE()
+		crcFrames = &io.LimitedReader{R: r, N: int64(bytesLeft - t.extendedHeader.PaddingSize)}
+		r = io.MultiReader(io.TeeReader(crcFrames, crc), r)
+	}
+
+	// Every frame consumes at least its header so a tag can't hold more
+	// frames than this, cap the loop in case the arithmetic ever goes wrong
+	maxFrames := bytesLeft/uint32(binary.Size(frame{})) + 1
+	if opts.ResyncFrames {
+		// Each frame can be preceded by a resync
+		maxFrames *= 2
+	}
+
+	var frames []id3v2.Frame
+	var padding uint32
+
+	for n := uint32(0); bytesLeft > 0; n++ {
 		f := frame{}
 
+		if n >= maxFrames {
+			return nil, fmt.Errorf("id3v230: expected at most %d frames in the tag", maxFrames)
+		}
+
+		// Anything too small to hold a frame header can only be padding
+		if !unbounded && bytesLeft < uint32(binary.Size(f)) {
+			padding = bytesLeft
+			break
+		}
+
 		if err := binary.Read(r, binary.BigEndian, &f); err != nil {
+			if unbounded && (err == io.EOF || err == io.ErrUnexpectedEOF) {
+				break
+			}
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return nil, fmt.Errorf("id3v230: unexpected EOF reading a frame header with %d bytes left in the tag: %w", bytesLeft, ErrTruncated)
+			}
 			return nil, err
 		}
 
 		bytesLeft = bytesLeft - uint32(binary.Size(f))
 
-		if f.ID[0] == 0 {
+		if opts.FrameSizeEndianness != nil {
+			var size [4]byte
+			binary.BigEndian.PutUint32(size[:], f.Size)
+			f.Size = opts.FrameSizeEndianness.Uint32(size[:])
+		}
+
+		// Without a size the frames end at the first header that isn't a
+		// frame, such as the start of the audio
+		if unbounded && !isFrameID(f.ID[:]) {
 			break
 		}
 
+		if f.ID[0] == 0 {
+			if !opts.RecoverFramesAfterPadding {
+				if !unbounded {
+					padding = bytesLeft + uint32(binary.Size(f))
+				}
+				break
+			}
+
+			// Look for a frame ID in what's left of the padding and resume
+			// decoding from there
+			rest, err := readRest(r, f, bytesLeft)
+			if err != nil {
+				return nil, err
+			}
+
+			i := indexFrameID(rest)
+			if i < 0 {
+				padding = uint32(len(rest))
+				break
+			}
+
+			r = bytes.NewReader(rest[i:])
+			bytesLeft = uint32(len(rest) - i)
+			continue
+		}
+
+		if opts.ResyncFrames && !unbounded && !isFrameID(f.ID[:]) {
+			// Skip to the next frame ID after the start of this header,
+			// giving up on the rest of the tag if there isn't one
+			rest, err := readRest(r, f, bytesLeft)
+			if err != nil {
+				return nil, err
+			}
+
+			i := indexFrameID(rest[1:])
+			if i < 0 {
+				break
+			}
+
+			r = bytes.NewReader(rest[1+i:])
+			bytesLeft = uint32(len(rest) - 1 - i)
+			continue
+		}
+
+		if synchSafeSizes {
+			if size := id3v2.SynchSafeToSize(f.Size); size != f.Size {
+				t.synchSafeFrames = append(t.synchSafeFrames, string(f.ID[:]))
+				f.Size = size
+			}
+		}
+
+		// Refuse frames that claim more data than the tag holds before
+		// allocating anything for them
+		if f.Size > bytesLeft {
+			return nil, fmt.Errorf("id3v230: frame '%s' size %d exceeds the %d bytes left in the tag", f.ID[:], f.Size, bytesLeft)
+		}
+
 		buf := &bytes.Buffer{}
 		n, err := io.CopyN(buf, r, int64(f.Size))
 		if err != nil {
 			if err == io.EOF {
-				return nil, fmt.Errorf("blah")
+				return nil, fmt.Errorf("id3v230: unexpected EOF reading frame '%s' (wanted %d bytes, got %d): %w", f.ID[:], f.Size, n, ErrTruncated)
 			}
 			return nil, err
 		}
@@ -147,65 +456,410 @@ func Decode(r io.Reader) (id3v2.Tag, error) {
 
 		bytesLeft = bytesLeft - f.Size
 
-		t.frameOrder = append(t.frameOrder, string(f.ID[:]))
-		t.frames[string(f.ID[:])] = buf.Bytes()
+		fe := id3v2.Frame{
+			ID:    string(f.ID[:]),
+			Flags: f.Flags,
+			Data:  buf.Bytes(),
+		}
+
+		// Keep the data the flags add to the frame header apart from the
+		// frame's own data
+		if n := extraHeaderSize(f.Flags); n > 0 && len(fe.Data) >= n {
+			fe.ExtraHeader = fe.Data[:n]
+			fe.Data = fe.Data[n:]
+		}
+
+		if alias, ok := frameAliases[fe.ID]; ok {
+			fe.ID = alias
+		}
+
+		if _, ok := SupportedFrames[fe.ID]; !ok {
+			fe.Raw = true
+		}
+
+		if opts.MaxFrames > 0 && len(frames) >= opts.MaxFrames {
+			return nil, fmt.Errorf("id3v230: expected at most %d frames in the tag", opts.MaxFrames)
+		}
+
+		frames = append(frames, fe)
+	}
+
+	t.List = framelist.New(format, frames, padding)
+
+	if crc != nil {
+		// Include any of the frames region the loop didn't get to
+		if _, err := io.Copy(crc, crcFrames); err != nil {
+			return nil, err
+		}
+		if sum := crc.Sum32(); sum != t.crc32 {
+			return nil, fmt.Errorf("id3v230: expected CRC-32 0x%08X but got 0x%08X", t.crc32, sum)
+		}
+	}
+
+	if opts.CheckPaddingSize && t.header.Flags&HeaderFlagExtendedHeader != 0 {
+		if t.extendedHeader.PaddingSize != padding {
+			return nil, fmt.Errorf("id3v230: extended header declares %d bytes of padding but got %d", t.extendedHeader.PaddingSize, padding)
+		}
 	}
 
 	return id3v2.Tag(t), nil
 }
 
+// The largest tag size a synchsafe integer can hold.
+const maxTagSize = 0x0FFFFFFF
+
+// framesFit returns true if walking the frame headers at the start of body
+// reaches padding or the end of the first size bytes without a frame running
+// past it. A size longer than body never fits since the stream ended first.
+func framesFit(body []byte, size uint32) bool {
+	hdrSize := uint32(binary.Size(frame{}))
+
+	if uint32(len(body)) < size {
+		return false
+	}
+
+	for i := uint32(0); i+hdrSize <= size; {
+		if i+hdrSize > uint32(len(body)) {
+			return false
+		}
+		if body[i] == 0 {
+			return true
+		}
+
+		i += hdrSize + binary.BigEndian.Uint32(body[i+4:i+8])
+		if i > size {
+			return false
+		}
+	}
+	return true
+}
+
+// frameSizesFit returns true if walking the frame headers of body with their
+// sizes read by frameSize lands on a frame ID each time, ending exactly at the
+// end of body or at padding that runs to it.
+func frameSizesFit(body []byte, frameSize func([]byte) uint32) bool {
+	hdrSize := binary.Size(frame{})
+
+	for i := 0; i < len(body); {
+		if body[i] == 0 {
+			return len(bytes.Trim(body[i:], "\x00")) == 0
+		}
+		if i+hdrSize > len(body) || !isFrameID(body[i:i+4]) {
+			return false
+		}
+
+		i += hdrSize + int(frameSize(body[i+4:i+8]))
+		if i > len(body) {
+			return false
+		}
+	}
+	return true
+}
+
+// synchSafeFrameSize reads a frame size as synchsafe, as ID3v2.4.0 encodes
+// it.
+func synchSafeFrameSize(b []byte) uint32 {
+	return id3v2.SynchSafeToSize(binary.BigEndian.Uint32(b))
+}
+
+// CRC32 returns the CRC-32 stored in the extended header of a decoded tag,
+// or false if it doesn't have one. DecodeOptions.VerifyCRC checks it against
+// the frames.
+func CRC32(tg id3v2.Tag) (uint32, bool) {
+	t, ok := tg.(*tag)
+	if !ok || t.extendedHeader.Flags&ExtendedHeaderFlagCRC32DataPresent == 0 {
+		return 0, false
+	}
+	return t.crc32, true
+}
+
+// PlainTagSize returns true if the tag was decoded with RepairTagSize and its
+// size was read as a plain integer rather than synchsafe.
+func PlainTagSize(tg id3v2.Tag) bool {
+	t, ok := tg.(*tag)
+	return ok && t.plainSize
+}
+
+// SynchSafeFrames returns the ID of each frame of a tag decoded with
+// RepairFrameSizes whose size was read as synchsafe rather than as a plain
+// integer. Frames smaller than 128 bytes read the same either way and aren't
+// included.
+func SynchSafeFrames(tg id3v2.Tag) []string {
+	if t, ok := tg.(*tag); ok {
+		return t.synchSafeFrames
+	}
+	return nil
+}
+
+// SizeAnomalies describes the sizes of the tag as decoded which weren't
+// encoded as ID3v2.3.0 requires.
+func (t *tag) SizeAnomalies() []string {
+	var anomalies []string
+	if t.plainSize {
+		anomalies = append(anomalies, "tag size is a plain integer rather than synchsafe")
+	}
+	for _, id := range t.synchSafeFrames {
+		anomalies = append(anomalies, fmt.Sprintf("frame %s has a synchsafe size as in ID3v2.4.0", id))
+	}
+	return anomalies
+}
+
+// hasExtendedHeader returns true if a tag was decoded with an extended header.
+func hasExtendedHeader(tg id3v2.Tag) bool {
+	t, ok := tg.(*tag)
+	return ok && t.header.Flags&HeaderFlagExtendedHeader != 0
+}
+
+// extraHeaderSize returns the number of bytes the frame flags add to the
+// frame header, which are counted in the frame size.
+//
+// Decompressed size      $xx xx xx xx  (compression)
+// Encryption method      $xx           (encryption)
+// Group identifier       $xx           (grouping identity)
+func extraHeaderSize(flags uint16) int {
+	n := 0
+	if flags&FrameFlagCompression != 0 {
+		n += 4
+	}
+	if flags&FrameFlagEncryption != 0 {
+		n++
+	}
+	if flags&FrameFlagGroupingIdentity != 0 {
+		n++
+	}
+	return n
+}
+
+// frameEntries returns every frame of a tag in order.
+func frameEntries(tg id3v2.Tag) []id3v2.Frame {
+	if t, ok := tg.(*tag); ok {
+		return t.Entries()
+	}
+	return framelist.Of(tg)
+}
+
+// indexFrameID returns the index of the first frame header in b, or -1 if
+// there isn't one. Frame IDs are made out of the characters A-Z and 0-9.
+func indexFrameID(b []byte) int {
+	hdrSize := binary.Size(frame{})
+
+	for i := 0; i+hdrSize <= len(b); i++ {
+		if isFrameID(b[i : i+4]) {
+			return i
+		}
+	}
+	return -1
+}
+
+func isFrameID(id []byte) bool {
+	if id[0] < 'A' || id[0] > 'Z' {
+		return false
+	}
+	for _, c := range id[1:] {
+		if (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// readRest returns the header of the frame f followed by the rest of the
+// tag, up to bytesLeft bytes, for searching it for the next frame.
+func readRest(r io.Reader, f frame, bytesLeft uint32) ([]byte, error) {
+	rest := &bytes.Buffer{}
+	if err := binary.Write(rest, binary.BigEndian, f); err != nil {
+		return nil, err
+	}
+	if _, err := io.CopyN(rest, r, int64(bytesLeft)); err != nil {
+		return nil, err
+	}
+	return rest.Bytes(), nil
+}
+
+// EncodeOptions control how EncodeWithOptions writes a tag. The zero value
+// encodes as Encode does.
+type EncodeOptions struct {
+	// BufferSize wraps the writer in a bufio.Writer of this size when
+	// greater than zero, so the header and frames reach the underlying
+	// writer in as few writes as possible. The buffer is flushed before
+	// returning.
+	BufferSize int
+
+	// Unsynchronise applies unsynchronisation to everything after the header
+	// and sets HeaderFlagUnsynchronisation, for strict players that reject
+	// tags containing false MPEG syncs.
+	Unsynchronise bool
+
+	// PaddingSize is the number of zero bytes written after the frames and
+	// counted in the tag size, leaving room for frames to be added later
+	// without rewriting the whole file.
+	PaddingSize uint32
+}
+
 func Encode(w io.Writer, tag id3v2.Tag) error {
+	return EncodeWithOptions(w, tag, EncodeOptions{})
+}
+
+func EncodeWithOptions(w io.Writer, tag id3v2.Tag, opts EncodeOptions) error {
+	var bw *bufio.Writer
+	if opts.BufferSize > 0 {
+		bw = bufio.NewWriterSize(w, opts.BufferSize)
+		w = bw
+	}
+
 	fBuf := &bytes.Buffer{}
 
-	for _, id := range tag.FrameOrder() {
-		// Check that the frame still exists
-		data, ok := tag.Frames()[id]
-		if !ok {
-			continue
+	for _, fe := range frameEntries(tag) {
+		if len(fe.ID) != 4 {
+			return fmt.Errorf("id3v230: expected frame ID of length 4 but got %d", len(fe.ID))
 		}
-
-		if len(id) != 4 {
-			return fmt.Errorf("id3v230: expected frame ID of length 4 but got %d", len(id))
+		if !isSupported(fe.ID) && !fe.Raw {
+			return fmt.Errorf("id3v230: unsupported frame ID '%s'", fe.ID)
 		}
-		if _, ok := SupportedFrames[id]; !ok {
-			return fmt.Errorf("id3v230: unsupported frame ID '%s'", id)
+
+		// Drop the extra header bytes if the flags no longer call for them
+		extra := fe.ExtraHeader
+		if len(extra) != extraHeaderSize(fe.Flags) {
+			extra = nil
 		}
 
 		f := frame{
-			Size:  uint32(len(data)),
-			Flags: 0,
+			Size:  uint32(len(extra) + len(fe.Data)),
+			Flags: fe.Flags,
 		}
-		copy(f.ID[:], []byte(id))
+		copy(f.ID[:], []byte(fe.ID))
 
 		if err := binary.Write(fBuf, binary.BigEndian, f); err != nil {
 			return err
 		}
 
-		if err := binary.Write(fBuf, binary.BigEndian, data); err != nil {
+		if _, err := fBuf.Write(extra); err != nil {
+			return err
+		}
+
+		if err := binary.Write(fBuf, binary.BigEndian, fe.Data); err != nil {
 			return err
 		}
 	}
 
 	h := header{
-		Version:   [2]byte{3, 0},
-		Flags:     0,
-		SynchSafe: id3v2.SizeToSynchSafe(uint32(fBuf.Len())),
+		Version: [2]byte{3, 0},
+		Flags:   0,
 	}
 	copy(h.ID[:], id3v2.FileIdentifier)
 
+	// Keep the extended header of tags decoded with one, the CRC isn't
+	// written
+	eBuf := &bytes.Buffer{}
+	if hasExtendedHeader(tag) {
+		eh := extendedHeader{
+			Flags:       0,
+			PaddingSize: opts.PaddingSize,
+		}
+		eh.Size = uint32(binary.Size(eh) - binary.Size(eh.Size))
+
+		if err := binary.Write(eBuf, binary.BigEndian, eh); err != nil {
+			return err
+		}
+		h.Flags |= HeaderFlagExtendedHeader
+	}
+
+	if uint64(eBuf.Len())+uint64(fBuf.Len())+uint64(opts.PaddingSize) > maxTagSize {
+		return fmt.Errorf("id3v230: tag size with %d bytes of padding exceeds the maximum of %d", opts.PaddingSize, maxTagSize)
+	}
+	fBuf.Write(make([]byte, opts.PaddingSize))
+
+	body := io.MultiReader(eBuf, fBuf)
+	size := eBuf.Len() + fBuf.Len()
+
+	// Unsynchronisation covers everything after the header and the size is
+	// of the unsynchronised bytes
+	if opts.Unsynchronise {
+		b := id3v2.Unsynchronise(append(eBuf.Bytes(), fBuf.Bytes()...))
+		body, size = bytes.NewReader(b), len(b)
+		h.Flags |= HeaderFlagUnsynchronisation
+
+		// The bytes unsynchronisation adds can take the tag past the limit
+		if size > maxTagSize {
+			return fmt.Errorf("id3v230: tag size with %d bytes of padding exceeds the maximum of %d", opts.PaddingSize, maxTagSize)
+		}
+	}
+
+	h.SynchSafe = id3v2.SizeToSynchSafe(uint32(size))
+
 	if err := binary.Write(w, binary.BigEndian, h); err != nil {
 		return err
 	}
 
-	if _, err := io.Copy(w, fBuf); err != nil && err != io.EOF {
+	if _, err := io.Copy(w, body); err != nil && err != io.EOF {
 		return err
 	}
 
+	if bw != nil {
+		return bw.Flush()
+	}
+
 	return nil
 }
 
+// EncodeToWAVChunk encodes the tag as a RIFF "id3 " chunk ready to be spliced
+// into a WAV file. The chunk is padded to an even number of bytes as required
+// by RIFF, the pad byte is not included in the chunk size.
+func EncodeToWAVChunk(tag id3v2.Tag) ([]byte, error) {
+	tBuf := &bytes.Buffer{}
+	if err := Encode(tBuf, tag); err != nil {
+		return nil, err
+	}
+
+	cBuf := &bytes.Buffer{}
+	cBuf.WriteString(id3v2.WAVChunkID)
+	if err := binary.Write(cBuf, binary.LittleEndian, uint32(tBuf.Len())); err != nil {
+		return nil, err
+	}
+	if tBuf.Len()%2 != 0 {
+		tBuf.WriteByte(0)
+	}
+	if _, err := io.Copy(cBuf, tBuf); err != nil {
+		return nil, err
+	}
+
+	return cBuf.Bytes(), nil
+}
+
 func init() {
-	id3v2.RegisterVersion(3, 0, Decode)
+	id3v2.RegisterVersionInfo(id3v2.VersionInfo{
+		Major:    3,
+		Revision: 0,
+		Decode:   Decode,
+		Encode:   Encode,
+		NewTag:   NewTag,
+	})
+}
+
+// TolerantFrames is a map of non-standard frames written by tools in the
+// wild, and their descriptions. Encode accepts them alongside SupportedFrames.
+var TolerantFrames = map[string]string{
+	"GRP1": "Grouping, written by iTunes",
+	"MVIN": "Movement number/count, written by iTunes",
+	"MVNM": "Movement name, written by iTunes",
+	"NCON": "MusicMatch binary data",
+	"TSOP": "Performer sort order, written by iTunes before ID3v2.4.0 added it",
+	"XSOP": "Performer sort order, written by early versions of iTunes",
+}
+
+// frameAliases maps non-standard frame IDs to the standard frame they're
+// decoded as.
+var frameAliases = map[string]string{
+	"XSOP": "TSOP",
+}
+
+// isSupported returns true if a frame ID may be encoded.
+func isSupported(id string) bool {
+	if _, ok := SupportedFrames[id]; ok {
+		return true
+	}
+	_, ok := TolerantFrames[id]
+	return ok
 }
 
 // SupportedFlags is a map of frames supported by ID3v2.3.0 and their descriptions.
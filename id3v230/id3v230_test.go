@@ -0,0 +1,1439 @@
+package id3v230
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"math"
+	"runtime"
+	"testing"
+
+	"github.com/jlubawy/go-id3v2"
+)
+
+// testTag is a minimal ID3v2.3.0 tag containing a single TIT2 frame.
+var testTag = []byte{
+	'I', 'D', '3', 3, 0, 0, 0, 0, 0, 15,
+	'T', 'I', 'T', '2', 0, 0, 0, 5, 0, 0,
+	0, 'T', 'e', 's', 't',
+}
+
+func TestEncodeToWAVChunk(t *testing.T) {
+	tag, err := Decode(bytes.NewReader(testTag))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := EncodeToWAVChunk(tag)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if id := string(b[0:4]); id != id3v2.WAVChunkID {
+		t.Errorf("expected chunk ID %q but got %q", id3v2.WAVChunkID, id)
+	}
+	if size := binary.LittleEndian.Uint32(b[4:8]); size != uint32(len(testTag)) {
+		t.Errorf("expected chunk size %d but got %d", len(testTag), size)
+	}
+	if len(b)%2 != 0 {
+		t.Errorf("expected chunk to be padded to an even length but got %d", len(b))
+	}
+	if !bytes.Equal(b[8:8+len(testTag)], testTag) {
+		t.Errorf("expected chunk data %v but got %v", testTag, b[8:8+len(testTag)])
+	}
+}
+
+func TestDecodeFrameSizeExceedsTag(t *testing.T) {
+	b := append([]byte{}, testTag...)
+	b[17] = 6 // TIT2 size one byte larger than the tag
+
+	if _, err := Decode(bytes.NewReader(b)); err == nil {
+		t.Error("expected an error for a frame larger than the tag")
+	}
+}
+
+func TestDecodeUnsynchronisedExtendedHeader(t *testing.T) {
+	b := []byte{
+		'I', 'D', '3', 3, 0, HeaderFlagUnsynchronisation | HeaderFlagExtendedHeader, 0, 0, 0, 26,
+		0, 0, 0, 6, 0x00, 0xFF, 0x00, 0, 0, 0, 0,
+		'T', 'I', 'T', '2', 0, 0, 0, 5, 0, 0,
+		0, 'T', 'e', 's', 't',
+	}
+
+	tg, err := Decode(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if flags := tg.(*tag).extendedHeader.Flags; flags != 0x00FF {
+		t.Errorf("expected extended header flags 0x00FF but got 0x%04X", flags)
+	}
+	if data := tg.Frames()["TIT2"]; !bytes.Equal(data, []byte("\x00Test")) {
+		t.Errorf("expected TIT2 frame %q but got %q", "\x00Test", data)
+	}
+}
+
+func TestNewTag(t *testing.T) {
+	tg, err := id3v2.NewTag(VersionString)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tg.SetFrame("TIT2", []byte("\x00Test"))
+
+	buf := &bytes.Buffer{}
+	if err := Encode(buf, tg); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), testTag) {
+		t.Errorf("expected encoded tag %v but got %v", testTag, buf.Bytes())
+	}
+
+	if _, err := id3v2.NewTag("id3v2.9.0"); err != id3v2.ErrVersion {
+		t.Errorf("expected ErrVersion but got %v", err)
+	}
+}
+
+func TestDecodeRecoverFramesAfterPadding(t *testing.T) {
+	b := []byte{
+		'I', 'D', '3', 3, 0, 0, 0, 0, 0, 40,
+		'T', 'I', 'T', '2', 0, 0, 0, 5, 0, 0,
+		0, 'T', 'e', 's', 't',
+		0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+		'T', 'P', 'E', '1', 0, 0, 0, 5, 0, 0,
+		0, 'T', 'e', 's', 't',
+	}
+
+	tg, err := Decode(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := tg.Frames()["TPE1"]; ok {
+		t.Error("expected TPE1 frame after the padding to be ignored")
+	}
+
+	tg, err = DecodeWithOptions(bytes.NewReader(b), DecodeOptions{RecoverFramesAfterPadding: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"TIT2", "TPE1"} {
+		if _, ok := tg.Frames()[id]; !ok {
+			t.Errorf("expected %s frame to be recovered", id)
+		}
+	}
+}
+
+func TestGetFrameFold(t *testing.T) {
+	tg, err := Decode(bytes.NewReader(testTag))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data, ok := tg.GetFrameFold("tit2"); !ok || !bytes.Equal(data, []byte("\x00Test")) {
+		t.Errorf("expected to find TIT2 frame using lowercase ID but got %q, %t", data, ok)
+	}
+	if _, ok := tg.GetFrameFold("tpe1"); ok {
+		t.Error("expected not to find a TPE1 frame")
+	}
+}
+
+func TestDecodeNotID3(t *testing.T) {
+	b := append([]byte{}, testTag...)
+	copy(b, "TAG")
+
+	if _, err := Decode(bytes.NewReader(b)); err != id3v2.ErrFormat {
+		t.Errorf("expected ErrFormat but got %v", err)
+	}
+}
+
+func TestDecodeCheckPaddingSize(t *testing.T) {
+	b := []byte{
+		'I', 'D', '3', 3, 0, HeaderFlagExtendedHeader, 0, 0, 0, 35,
+		0, 0, 0, 6, 0, 0, 0, 0, 0, 10,
+		'T', 'I', 'T', '2', 0, 0, 0, 5, 0, 0,
+		0, 'T', 'e', 's', 't',
+		0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	}
+
+	tg, err := DecodeWithOptions(bytes.NewReader(b), DecodeOptions{CheckPaddingSize: true})
+	if err != nil {
+		t.Errorf("expected no error for matching padding size but got %v", err)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := Encode(buf, tg); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := DecodeWithOptions(buf, DecodeOptions{CheckPaddingSize: true}); err != nil {
+		t.Errorf("expected encoded padding size to match but got %v", err)
+	}
+
+	b[19] = 20
+	if _, err := DecodeWithOptions(bytes.NewReader(b), DecodeOptions{CheckPaddingSize: true}); err == nil {
+		t.Error("expected an error for a mismatched padding size")
+	}
+}
+
+func TestEncodeFrameFlags(t *testing.T) {
+	b := append([]byte{}, testTag...)
+	b[18] = byte(FrameFlagTagAlterPreservation >> 8)
+
+	tg, err := Decode(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if flags := tg.FrameFlags("TIT2"); flags != FrameFlagTagAlterPreservation {
+		t.Errorf("expected TIT2 flags 0x%04X but got 0x%04X", FrameFlagTagAlterPreservation, flags)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := Encode(buf, tg); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), b) {
+		t.Errorf("expected frame flags to be preserved, encoded %v but got %v", b, buf.Bytes())
+	}
+
+	tg.SetFrameFlags("TIT2", 0)
+
+	buf.Reset()
+	if err := Encode(buf, tg); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), testTag) {
+		t.Errorf("expected cleared frame flags, encoded %v but got %v", testTag, buf.Bytes())
+	}
+}
+
+func TestDecodeReadFramesWithoutSize(t *testing.T) {
+	b := append([]byte{}, testTag...)
+	b[9] = 0
+
+	tg, err := Decode(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tg.Frames()) != 0 {
+		t.Errorf("expected no frames for a tag of size 0 but got %d", len(tg.Frames()))
+	}
+
+	tg, err = DecodeWithOptions(bytes.NewReader(b), DecodeOptions{ReadFramesWithoutSize: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data := tg.Frames()["TIT2"]; !bytes.Equal(data, []byte("\x00Test")) {
+		t.Errorf("expected TIT2 frame %q but got %q", "\x00Test", data)
+	}
+
+	// The frames end at the audio, which isn't read through even if the tag
+	// is unsynchronised
+	audio := append([]byte{0xFF, 0xFB, 0x90, 0x00}, bytes.Repeat([]byte{0x55}, 1<<16)...)
+	for _, flags := range []byte{0, HeaderFlagUnsynchronisation} {
+		b[5] = flags
+		r := bytes.NewReader(append(append([]byte{}, b...), audio...))
+
+		tg, err = DecodeWithOptions(r, DecodeOptions{ReadFramesWithoutSize: true})
+		if err != nil {
+			t.Fatalf("flags 0x%02X: %v", flags, err)
+		}
+		if order := tg.FrameOrder(); len(order) != 1 || order[0] != "TIT2" {
+			t.Errorf("flags 0x%02X: expected only the TIT2 frame but got %v", flags, order)
+		}
+		if r.Len() == 0 {
+			t.Errorf("flags 0x%02X: expected the audio not to be read through", flags)
+		}
+	}
+}
+
+func TestDecodeFrameSizeEndianness(t *testing.T) {
+	b := append([]byte{}, testTag...)
+	b[14], b[17] = 5, 0
+
+	if _, err := Decode(bytes.NewReader(b)); err == nil {
+		t.Error("expected an error for a little-endian frame size")
+	}
+
+	tg, err := DecodeWithOptions(bytes.NewReader(b), DecodeOptions{FrameSizeEndianness: binary.LittleEndian})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data := tg.Frames()["TIT2"]; !bytes.Equal(data, []byte("\x00Test")) {
+		t.Errorf("expected TIT2 frame %q but got %q", "\x00Test", data)
+	}
+}
+
+func TestFlatten(t *testing.T) {
+	tg := NewTag()
+	tg.SetFrame("TIT2", []byte("\x00Test"))
+	tg.SetFrame("WOAR", []byte("http://example.com"))
+	tg.SetFrame("PRIV", []byte("owner\x00\x01\x02"))
+
+	expected := []id3v2.KV{
+		{Key: "Title/songname/content description", Value: "Test"},
+		{Key: "Official artist/performer webpage", Value: "http://example.com"},
+		{Key: "Private frame", Value: "<binary data, 8 bytes>"},
+	}
+
+	kvs := tg.Flatten()
+	if len(kvs) != len(expected) {
+		t.Fatalf("expected %v but got %v", expected, kvs)
+	}
+	for i := range expected {
+		if kvs[i] != expected[i] {
+			t.Errorf("expected %v but got %v", expected[i], kvs[i])
+		}
+	}
+}
+
+func TestEncodeUnsupportedFramePassthrough(t *testing.T) {
+	b := []byte{
+		'I', 'D', '3', 3, 0, 0, 0, 0, 0, 27,
+		'T', 'I', 'T', '2', 0, 0, 0, 5, 0, 0,
+		0, 'T', 'e', 's', 't',
+		'X', 'P', 'R', 'P', 0, 0, 0, 2, 0x40, 0x00,
+		0xCA, 0xFE,
+	}
+
+	tg, err := Decode(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tg.SetFrame("TIT2", []byte("\x00Edit"))
+	copy(b[21:25], "Edit")
+
+	buf := &bytes.Buffer{}
+	if err := Encode(buf, tg); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), b) {
+		t.Errorf("expected unsupported frame to be passed through, encoded %v but got %v", b, buf.Bytes())
+	}
+
+	// Setting every frame at once keeps the unchanged unsupported frame
+	tg.SetFrames(tg.Frames())
+	buf.Reset()
+	if err := Encode(buf, tg); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), b) {
+		t.Errorf("expected unsupported frame to be passed through SetFrames, encoded %v but got %v", b, buf.Bytes())
+	}
+
+	tg = NewTag()
+	tg.SetFrame("XPRP", []byte{0xCA, 0xFE})
+	if err := Encode(&bytes.Buffer{}, tg); err == nil {
+		t.Error("expected an error encoding an unsupported frame that wasn't decoded")
+	}
+}
+
+func TestDecodeExtendedHeaderDeclaredSize(t *testing.T) {
+	// The CRC flag is set and the extended header is 4 bytes larger than
+	// the fields it holds
+	b := []byte{
+		'I', 'D', '3', 3, 0, HeaderFlagExtendedHeader, 0, 0, 0, 33,
+		0, 0, 0, 14, 0x80, 0, 0, 0, 0, 0, 0xDE, 0xAD, 0xBE, 0xEF, 1, 2, 3, 4,
+		'T', 'I', 'T', '2', 0, 0, 0, 5, 0, 0,
+		0, 'T', 'e', 's', 't',
+	}
+
+	tg, err := Decode(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if crc := tg.(*tag).crc32; crc != 0xDEADBEEF {
+		t.Errorf("expected CRC 0xDEADBEEF but got 0x%08X", crc)
+	}
+	if data := tg.Frames()["TIT2"]; !bytes.Equal(data, []byte("\x00Test")) {
+		t.Errorf("expected TIT2 frame %q but got %q", "\x00Test", data)
+	}
+}
+
+func TestDecodeVerifyCRC(t *testing.T) {
+	frames := []byte{
+		'T', 'I', 'T', '2', 0, 0, 0, 5, 0, 0,
+		0, 'T', 'e', 's', 't',
+	}
+
+	b := []byte{
+		'I', 'D', '3', 3, 0, HeaderFlagExtendedHeader, 0, 0, 0, 39,
+		0, 0, 0, 10, 0x80, 0, 0, 0, 0, 10, 0, 0, 0, 0,
+	}
+	binary.BigEndian.PutUint32(b[20:24], crc32.ChecksumIEEE(frames))
+	b = append(b, frames...)
+	b = append(b, make([]byte, 10)...)
+
+	tg, err := DecodeWithOptions(bytes.NewReader(b), DecodeOptions{VerifyCRC: true})
+	if err != nil {
+		t.Errorf("expected no error for a correct CRC but got %v", err)
+	}
+	if sum, ok := CRC32(tg); !ok || sum != crc32.ChecksumIEEE(frames) {
+		t.Errorf("expected CRC-32 0x%08X but got 0x%08X, %t", crc32.ChecksumIEEE(frames), sum, ok)
+	}
+
+	tg, err = Decode(bytes.NewReader(testTag))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := CRC32(tg); ok {
+		t.Error("expected no CRC-32 without an extended header")
+	}
+
+	b[len(b)-11] = 'T'
+	if _, err := DecodeWithOptions(bytes.NewReader(b), DecodeOptions{VerifyCRC: true}); err == nil {
+		t.Error("expected an error for corrupted frames")
+	}
+}
+
+func TestDecodeCompressedFrameSize(t *testing.T) {
+	b := []byte{
+		'I', 'D', '3', 3, 0, 0, 0, 0, 0, 19,
+		'T', 'I', 'T', '2', 0, 0, 0, 9, 0, byte(FrameFlagCompression),
+		0, 0, 0, 5, 0xCA, 0xFE, 0xBA, 0xBE, 0x00,
+	}
+
+	tg, err := Decode(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data := tg.Frames()["TIT2"]; !bytes.Equal(data, b[24:]) {
+		t.Errorf("expected the decompressed size to be removed from the frame data but got %v", data)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := Encode(buf, tg); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), b) {
+		t.Errorf("expected the decompressed size to be written back, encoded %v but got %v", b, buf.Bytes())
+	}
+}
+
+func TestSetFrameClearsFormatFlags(t *testing.T) {
+	b := []byte{
+		'I', 'D', '3', 3, 0, 0, 0, 0, 0, 38,
+		'T', 'I', 'T', '2', 0, 0, 0, 9, byte(FrameFlagTagAlterPreservation >> 8), byte(FrameFlagCompression),
+		0, 0, 0, 5, 0xCA, 0xFE, 0xBA, 0xBE, 0x00,
+		'T', 'P', 'E', '1', 0, 0, 0, 9, 0, byte(FrameFlagCompression),
+		0, 0, 0, 5, 0xDE, 0xAD, 0xBE, 0xEF, 0x00,
+	}
+
+	tg, err := Decode(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// New data isn't compressed, only the tag alter flag still holds
+	tg.SetFrame("TIT2", []byte("\x00Test"))
+
+	// Unchanged data is still compressed
+	tg.SetFrames(map[string][]byte{"TIT2": []byte("\x00Test"), "TPE1": b[43:]})
+
+	expected := []byte{
+		'I', 'D', '3', 3, 0, 0, 0, 0, 0, 34,
+		'T', 'I', 'T', '2', 0, 0, 0, 5, byte(FrameFlagTagAlterPreservation >> 8), 0,
+		0, 'T', 'e', 's', 't',
+		'T', 'P', 'E', '1', 0, 0, 0, 9, 0, byte(FrameFlagCompression),
+		0, 0, 0, 5, 0xDE, 0xAD, 0xBE, 0xEF, 0x00,
+	}
+
+	buf := &bytes.Buffer{}
+	if err := Encode(buf, tg); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), expected) {
+		t.Errorf("expected %v but got %v", expected, buf.Bytes())
+	}
+	if size := tg.Size(); size != uint32(len(expected)) {
+		t.Errorf("expected size %d but got %d", len(expected), size)
+	}
+
+	tg.SetFrames(map[string][]byte{"TIT2": []byte("\x00Test"), "TPE1": []byte("\x00Artist")})
+	if flags := tg.FrameFlags("TPE1"); flags != 0 {
+		t.Errorf("expected TPE1 flags 0x0000 once its data changed but got 0x%04X", flags)
+	}
+}
+
+func TestEncodeInterleavedDuplicates(t *testing.T) {
+	frames := [][]byte{
+		[]byte("APIC\x00\x00\x00\x0C\x00\x00\x00image/png\x00\x03"),
+		[]byte("COMM\x00\x00\x00\x06\x00\x00\x00engA\x00"),
+		[]byte("APIC\x00\x00\x00\x0C\x00\x00\x00image/png\x00\x04"),
+		[]byte("PRIV\x00\x00\x00\x02\x00\x00a\x00"),
+		[]byte("COMM\x00\x00\x00\x06\x00\x00\x00engB\x00"),
+	}
+
+	body := bytes.Join(frames, nil)
+	b := append([]byte{'I', 'D', '3', 3, 0, 0, 0, 0, 0, byte(len(body))}, body...)
+
+	tg, err := Decode(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"APIC", "COMM", "APIC", "PRIV", "COMM"}
+	order := tg.FrameOrder()
+	if len(order) != len(expected) {
+		t.Fatalf("expected frame order %v but got %v", expected, order)
+	}
+	for i := range expected {
+		if order[i] != expected[i] {
+			t.Errorf("expected frame order %v but got %v", expected, order)
+			break
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	if err := Encode(buf, tg); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), b) {
+		t.Errorf("expected interleaved duplicates to round-trip, encoded %v but got %v", b, buf.Bytes())
+	}
+}
+
+func TestDecodeZeroSizeFrames(t *testing.T) {
+	b := []byte{
+		'I', 'D', '3', 3, 0, 0, 0, 0, 0, 30,
+		'T', 'I', 'T', '2', 0, 0, 0, 0, 0, 0,
+		'T', 'P', 'E', '1', 0, 0, 0, 0, 0, 0,
+		0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	}
+
+	tg, err := Decode(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if order := tg.FrameOrder(); len(order) != 2 {
+		t.Errorf("expected 2 empty frames but got %v", order)
+	}
+	if p := tg.Padding(); p != 10 {
+		t.Errorf("expected 10 bytes of padding but got %d", p)
+	}
+}
+
+func TestRoundTripDuplicateFrames(t *testing.T) {
+	b := []byte{
+		'I', 'D', '3', 3, 0, 0, 0, 0, 0, 37,
+		'C', 'O', 'M', 'M', 0, 0, 0, 8, 0, 0,
+		0, 'e', 'n', 'g', 0, 'O', 'n', 'e',
+		'C', 'O', 'M', 'M', 0, 0, 0, 9, 0, 0,
+		0, 'e', 'n', 'g', 0, 'T', 'w', 'o', '!',
+	}
+
+	tg, err := Decode(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := Encode(buf, tg); err != nil {
+		t.Fatal(err)
+	}
+
+	tg, err = Decode(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	comments := tg.FramesByID("COMM")
+	if len(comments) != 2 {
+		t.Fatalf("expected 2 COMM frames but got %d", len(comments))
+	}
+	if !bytes.Equal(comments[0], b[20:28]) || !bytes.Equal(comments[1], b[38:]) {
+		t.Errorf("expected COMM frames %q and %q but got %q", b[20:28], b[38:], comments)
+	}
+}
+
+func TestTolerantFrames(t *testing.T) {
+	b := []byte{
+		'I', 'D', '3', 3, 0, 0, 0, 0, 0, 15,
+		'X', 'S', 'O', 'P', 0, 0, 0, 5, 0, 0,
+		0, 'S', 'o', 'r', 't',
+	}
+
+	tg, err := Decode(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := string(tg.Frames()["TSOP"]); s != "\x00Sort" {
+		t.Errorf("expected XSOP decoded as TSOP '\\x00Sort' but got %v", tg.FrameOrder())
+	}
+
+	tg.SetFrame("NCON", []byte{1, 2, 3})
+	tg.SetFrame("TSOP", []byte("\x00Sorted"))
+
+	buf := &bytes.Buffer{}
+	if err := Encode(buf, tg); err != nil {
+		t.Fatal(err)
+	}
+
+	tg.SetFrame("ABCD", []byte{1})
+	if err := Encode(buf, tg); err == nil {
+		t.Error("expected an error for an unsupported frame")
+	}
+}
+
+func TestDecodeAPIC(t *testing.T) {
+	png := []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1A, '\n'}
+
+	data := []byte("\x01image/png\x00\x03\xFF\xFEC\x00o\x00v\x00e\x00r\x00\x00\x00")
+	data = append(data, png...)
+
+	pic, err := DecodeAPIC(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if pic.Encoding != 0x01 || pic.MIMEType != "image/png" || pic.PictureType != 0x03 || pic.Description != "Cover" {
+		t.Errorf("expected UTF-16 image/png cover front 'Cover' but got %+v", pic)
+	}
+	if !bytes.Equal(pic.Data, png) {
+		t.Errorf("expected picture data %v but got %v", png, pic.Data)
+	}
+
+	if _, err := DecodeAPIC([]byte("\x00image/pn")); err == nil {
+		t.Error("expected an error for a truncated frame")
+	}
+}
+
+func TestRoundTripAPIC(t *testing.T) {
+	jpeg := []byte{0xFF, 0xD8, 0xFF, 0xE0, 0x00, 0x10, 'J', 'F', 'I', 'F', 0x00, 0xFF, 0xD9}
+
+	for _, enc := range []id3v2.Encoding{id3v2.EncodingISO88591, id3v2.EncodingUTF16, id3v2.EncodingUTF8} {
+		pic := APIC{
+			Encoding:    enc,
+			MIMEType:    "image/jpeg",
+			PictureType: 0x03,
+			Description: "Front Cover",
+			Data:        jpeg,
+		}
+
+		data, err := EncodeAPIC(pic)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := DecodeAPIC(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got.Encoding != pic.Encoding || got.MIMEType != pic.MIMEType || got.PictureType != pic.PictureType || got.Description != pic.Description || got.IsLink {
+			t.Errorf("expected %+v but got %+v", pic, *got)
+		}
+		if !bytes.Equal(got.Data, jpeg) {
+			t.Errorf("encoding $%02X: expected picture data %v but got %v", enc, jpeg, got.Data)
+		}
+	}
+
+	if _, err := EncodeAPIC(APIC{PictureType: 0x03, Data: jpeg}); err == nil {
+		t.Error("expected an error for an empty MIME type")
+	}
+	if _, err := EncodeAPIC(APIC{MIMEType: "image/jpeg", PictureType: 0x15, Data: jpeg}); err == nil {
+		t.Error("expected an error for a picture type above $14")
+	}
+}
+
+func TestGetFrameCanonical(t *testing.T) {
+	b := append([]byte{}, testTag...)
+	copy(b[10:14], "tit2")
+
+	tg, err := Decode(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data, ok := tg.GetFrame("TIT2"); !ok || !bytes.Equal(data, []byte("\x00Test")) {
+		t.Errorf("expected to find tit2 frame using TIT2 but got %q, %t", data, ok)
+	}
+	if order := tg.FrameOrder(); order[0] != "tit2" {
+		t.Errorf("expected the original ID tit2 to be kept but got %v", order)
+	}
+
+	tg.SetFrame("TPE1", []byte("\x00Artist"))
+	if _, ok := tg.GetFrame("TPE1"); !ok {
+		t.Error("expected to find a TPE1 frame added after the index was built")
+	}
+	if _, ok := tg.GetFrame("TALB"); ok {
+		t.Error("expected not to find a TALB frame")
+	}
+}
+
+func TestDecodeCOMM(t *testing.T) {
+	c, err := DecodeCOMM([]byte("\x00engShort\x00The full comment"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Language != "eng" || c.Description != "Short" || c.Text != "The full comment" {
+		t.Errorf("expected eng, 'Short', 'The full comment' but got %+v", c)
+	}
+
+	// "説明" and "こんにちは" in UTF-16 little-endian
+	data := []byte("\x01jpn\xFF\xFE\xAC\x8A\x0E\x66\x00\x00\xFF\xFE\x53\x30\x93\x30\x6B\x30\x61\x30\x6F\x30")
+	if c, err = DecodeCOMM(data); err != nil {
+		t.Fatal(err)
+	}
+	if c.Language != "jpn" || c.Description != "説明" || c.Text != "こんにちは" {
+		t.Errorf("expected jpn, '説明', 'こんにちは' but got %+v", c)
+	}
+
+	if _, err := DecodeCOMM([]byte("\x00en")); err == nil {
+		t.Error("expected an error for a truncated frame")
+	}
+}
+
+// countingWriter counts the calls made to Write.
+type countingWriter struct {
+	bytes.Buffer
+	writes int
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	return w.Buffer.Write(p)
+}
+
+func TestEncodeBufferSize(t *testing.T) {
+	tg, err := Decode(bytes.NewReader(testTag))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := &countingWriter{}
+	if err := EncodeWithOptions(w, tg, EncodeOptions{BufferSize: 4096}); err != nil {
+		t.Fatal(err)
+	}
+
+	if w.writes != 1 {
+		t.Errorf("expected 1 write but got %d", w.writes)
+	}
+	if !bytes.Equal(w.Bytes(), testTag) {
+		t.Errorf("expected %v but got %v", testTag, w.Bytes())
+	}
+}
+
+func TestRoundTripUSLT(t *testing.T) {
+	text := "First verse,\nsecond line.\n\nSecond verse,\nlast line."
+
+	for _, enc := range []id3v2.Encoding{id3v2.EncodingISO88591, id3v2.EncodingUTF16} {
+		l := Lyrics{
+			Encoding:    enc,
+			Language:    "eng",
+			Description: "Lyrics",
+			Text:        text,
+		}
+
+		data, err := EncodeUSLT(l)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := DecodeUSLT(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if *got != l {
+			t.Errorf("expected %+v but got %+v", l, *got)
+		}
+	}
+
+	if _, err := EncodeUSLT(Lyrics{Language: "en"}); err == nil {
+		t.Error("expected an error for a two letter language")
+	}
+}
+
+func TestRoundTripTXXX(t *testing.T) {
+	tests := []struct {
+		desc, value string
+		enc         id3v2.Encoding
+	}{
+		{"replaygain_track_gain", "-6.50 dB", id3v2.EncodingISO88591},
+		{"MusicBrainz Album Id ☃", "5c7b5ffd-9ae8-4e4e-8c6c-4e6b1a2b8a11", id3v2.EncodingUTF16},
+	}
+
+	tg := NewTag()
+	for _, test := range tests {
+		data, err := EncodeTXXX(test.desc, test.value, test.enc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id3v2.Encoding(data[0]) != test.enc {
+			t.Errorf("expected encoding $%02X but got $%02X", byte(test.enc), data[0])
+		}
+		tg.AddFrame("TXXX", data)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := Encode(buf, tg); err != nil {
+		t.Fatal(err)
+	}
+	tg, err := Decode(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	frames := tg.FramesByID("TXXX")
+	if len(frames) != len(tests) {
+		t.Fatalf("expected %d TXXX frames but got %d", len(tests), len(frames))
+	}
+	for i, test := range tests {
+		desc, value, err := DecodeTXXX(frames[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if desc != test.desc || value != test.value {
+			t.Errorf("expected '%s: %s' but got '%s: %s'", test.desc, test.value, desc, value)
+		}
+	}
+}
+
+func TestRoundTripWXXX(t *testing.T) {
+	desc, url := "Ünïcode ☃", "http://example.com/a?b=c"
+
+	data, err := EncodeWXXX(desc, url, id3v2.EncodingUTF16)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The URL follows the double null of the UTF-16 description untouched
+	if !bytes.HasSuffix(data, append([]byte{0, 0}, url...)) {
+		t.Errorf("expected the URL to be written as ISO-8859-1 but got %v", data)
+	}
+
+	d, u, err := DecodeWXXX(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d != desc || u != url {
+		t.Errorf("expected '%s', '%s' but got '%s', '%s'", desc, url, d, u)
+	}
+
+	if _, err := EncodeWXXX("", "http://example.com/☃", id3v2.EncodingUTF16); err == nil {
+		t.Error("expected an error for a URL that isn't ISO-8859-1")
+	}
+}
+
+func TestDecodeURLFrame(t *testing.T) {
+	b := []byte{
+		'I', 'D', '3', 3, 0, 0, 0, 0, 0, 34,
+		'W', 'O', 'A', 'R', 0, 0, 0, 24, 0, 0,
+	}
+	b = append(b, "http://example.com/\x00junk"...)
+
+	tg, err := Decode(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := DecodeURLFrame(tg.Frames()["WOAR"]); s != "http://example.com/" {
+		t.Errorf("expected 'http://example.com/' but got '%s'", s)
+	}
+
+	// The first byte is part of the URL, not an encoding byte
+	if s := DecodeURLFrame([]byte("\x01ttp")); s != "\x01ttp" {
+		t.Errorf("expected '\\x01ttp' but got %q", s)
+	}
+
+	tests := map[string]bool{
+		"WOAR": true,
+		"WCOM": true,
+		"WXXX": false,
+		"TIT2": false,
+		"WABC": false,
+	}
+	for id, expected := range tests {
+		if ok := IsURLFrame(id); ok != expected {
+			t.Errorf("%s: expected %t but got %t", id, expected, ok)
+		}
+	}
+}
+
+func TestDecodeMaxFrames(t *testing.T) {
+	b := []byte{'I', 'D', '3', 3, 0, 0, 0, 0, 0, 33}
+	for i := 0; i < 3; i++ {
+		b = append(b, 'T', 'I', 'T', '1'+byte(i), 0, 0, 0, 1, 0, 0, 0)
+	}
+
+	if _, err := DecodeWithOptions(bytes.NewReader(b), DecodeOptions{MaxFrames: 3}); err != nil {
+		t.Errorf("expected 3 frames to decode but got %v", err)
+	}
+	if _, err := DecodeWithOptions(bytes.NewReader(b), DecodeOptions{MaxFrames: 2}); err == nil {
+		t.Error("expected an error for more than 2 frames")
+	}
+}
+
+func TestDecodeUnsynchronisedFrame(t *testing.T) {
+	// A PRIV frame holding $FF E0 $FF 00, unsynchronised to $FF 00 E0 $FF 00 00
+	// with the frame size counting the bytes after resynchronisation
+	b := []byte{
+		'I', 'D', '3', 3, 0, HeaderFlagUnsynchronisation, 0, 0, 0, 31,
+		'P', 'R', 'I', 'V', 0, 0, 0, 4, 0, 0,
+		0xFF, 0x00, 0xE0, 0xFF, 0x00, 0x00,
+		'T', 'I', 'T', '2', 0, 0, 0, 5, 0, 0,
+		0, 'T', 'e', 's', 't',
+	}
+
+	tg, err := Decode(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data := tg.Frames()["PRIV"]; !bytes.Equal(data, []byte{0xFF, 0xE0, 0xFF, 0x00}) {
+		t.Errorf("expected PRIV [255 224 255 0] but got %v", data)
+	}
+	if data := tg.Frames()["TIT2"]; !bytes.Equal(data, []byte("\x00Test")) {
+		t.Errorf("expected the following TIT2 frame %q but got %q", "\x00Test", data)
+	}
+}
+
+func TestDecodeRepairTagSize(t *testing.T) {
+	// A 150 byte TIT2 frame with the tag size 160 written as a plain integer,
+	// which as synchsafe reads as 32
+	b := []byte{
+		'I', 'D', '3', 3, 0, 0, 0, 0, 0, 0xA0,
+		'T', 'I', 'T', '2', 0, 0, 0, 150, 0, 0,
+	}
+	b = append(b, 0)
+	b = append(b, bytes.Repeat([]byte{'a'}, 149)...)
+
+	if _, err := Decode(bytes.NewReader(b)); err == nil {
+		t.Error("expected an error without the repair")
+	}
+
+	tg, err := DecodeWithOptions(bytes.NewReader(b), DecodeOptions{RepairTagSize: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(tg.Frames()["TIT2"]); n != 150 {
+		t.Errorf("expected TIT2 of 150 bytes but got %d", n)
+	}
+	if !PlainTagSize(tg) {
+		t.Error("expected the size to be read as a plain integer")
+	}
+	if size := tg.Size(); size != uint32(len(b)) {
+		t.Errorf("expected size %d but got %d", len(b), size)
+	}
+
+	// A valid synchsafe size is left alone
+	if tg, err = DecodeWithOptions(bytes.NewReader(append(append([]byte{}, testTag...), b...)), DecodeOptions{RepairTagSize: true}); err != nil {
+		t.Fatal(err)
+	}
+	if PlainTagSize(tg) {
+		t.Error("expected the synchsafe size to be used")
+	}
+
+	// A huge plain size only buffers what the stream holds
+	huge := append([]byte{}, b...)
+	copy(huge[6:10], []byte{0x0F, 0xFF, 0xFF, 0xFF})
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	DecodeWithOptions(bytes.NewReader(huge), DecodeOptions{RepairTagSize: true})
+	runtime.ReadMemStats(&after)
+	if n := after.TotalAlloc - before.TotalAlloc; n > 1<<20 {
+		t.Errorf("expected a truncated tag to allocate little but got %d bytes", n)
+	}
+
+	if _, err := DecodeWithOptions(bytes.NewReader(b), DecodeOptions{RepairTagSize: true, ReadFramesWithoutSize: true}); err == nil {
+		t.Error("expected an error combining RepairTagSize and ReadFramesWithoutSize")
+	}
+}
+
+// synchSafeFrameSizeTag is a 200 byte TIT2 frame with its size written
+// synchsafe as in ID3v2.4.0, 0x00000148, which as a plain integer reads as 328,
+// followed by a TPE1 frame.
+func synchSafeFrameSizeTag() []byte {
+	b := []byte{
+		'I', 'D', '3', 3, 0, 0, 0, 0, 1, 97,
+		'T', 'I', 'T', '2', 0, 0, 1, 72, 0, 0,
+	}
+	b = append(b, 0)
+	b = append(b, bytes.Repeat([]byte{'a'}, 199)...)
+	return append(b, 'T', 'P', 'E', '1', 0, 0, 0, 5, 0, 0, 0, 'B', 'a', 'n', 'd')
+}
+
+func TestDecodeRepairFrameSizes(t *testing.T) {
+	b := synchSafeFrameSizeTag()
+
+	if _, err := Decode(bytes.NewReader(b)); err == nil {
+		t.Error("expected an error without the repair")
+	}
+
+	tg, err := DecodeWithOptions(bytes.NewReader(b), DecodeOptions{RepairFrameSizes: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(tg.Frames()["TIT2"]); n != 200 {
+		t.Errorf("expected TIT2 of 200 bytes but got %d", n)
+	}
+	if data := tg.Frames()["TPE1"]; string(data) != "\x00Band" {
+		t.Errorf("expected TPE1 %q but got %q", "\x00Band", data)
+	}
+	if ids := SynchSafeFrames(tg); len(ids) != 1 || ids[0] != "TIT2" {
+		t.Errorf("expected only TIT2 to have a synchsafe size but got %v", ids)
+	}
+
+	// Plain sizes that line up are left alone
+	if tg, err = DecodeWithOptions(bytes.NewReader(testTag), DecodeOptions{RepairFrameSizes: true}); err != nil {
+		t.Fatal(err)
+	}
+	if ids := SynchSafeFrames(tg); len(ids) != 0 {
+		t.Errorf("expected no synchsafe frame sizes but got %v", ids)
+	}
+}
+
+func TestRoundTripUnsynchronise(t *testing.T) {
+	data := []byte{0xFF, 0xE0, 0xFF, 0x00, 0xFF, 0x12, 0xFF}
+
+	tg := NewTag()
+	tg.SetFrame("PRIV", data)
+	tg.SetFrame("TIT2", []byte("\x00Test"))
+
+	buf := &bytes.Buffer{}
+	if err := EncodeWithOptions(buf, tg, EncodeOptions{Unsynchronise: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	b := buf.Bytes()
+	if b[5]&HeaderFlagUnsynchronisation == 0 {
+		t.Error("expected the unsynchronisation flag to be set")
+	}
+	for i := 10; i+1 < len(b); i++ {
+		if b[i] == 0xFF && b[i+1] >= 0xE0 {
+			t.Errorf("expected no false sync but got $FF %02X at %d", b[i+1], i)
+		}
+	}
+
+	tg, err := Decode(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tg.Frames()["PRIV"]; !bytes.Equal(got, data) {
+		t.Errorf("expected PRIV %v but got %v", data, got)
+	}
+	if got := tg.Frames()["TIT2"]; !bytes.Equal(got, []byte("\x00Test")) {
+		t.Errorf("expected TIT2 %q but got %q", "\x00Test", got)
+	}
+}
+
+func TestCompactFrameOrder(t *testing.T) {
+	tg := NewTag()
+	tg.AddFrame("COMM", []byte("\x00eng\x00One"))
+	tg.AddFrame("COMM", []byte("\x00eng\x00One"))
+	tg.AddFrame("COMM", []byte("\x00eng\x00Two"))
+	tg.AddFrame("TIT2", []byte("\x00Test"))
+	tg.AddFrame("COMM", []byte("\x00eng\x00Two"))
+
+	tg.CompactFrameOrder()
+
+	expected := []string{"COMM", "COMM", "TIT2", "COMM"}
+	order := tg.FrameOrder()
+	if len(order) != len(expected) {
+		t.Fatalf("expected %v but got %v", expected, order)
+	}
+	for i := range expected {
+		if order[i] != expected[i] {
+			t.Fatalf("expected %v but got %v", expected, order)
+		}
+	}
+	if comments := tg.FramesByID("COMM"); string(comments[1]) != "\x00eng\x00Two" {
+		t.Errorf("expected the second comment to be kept but got %q", comments)
+	}
+
+	// Compacting a decoded tag without repeats leaves it as decoded
+	b := append([]byte{}, testTag...)
+	b[9] += 10
+	b = append(b, make([]byte, 10)...)
+
+	tg, err := Decode(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tg.CompactFrameOrder()
+	if padding := tg.Padding(); padding != 10 {
+		t.Errorf("expected padding 10 but got %d", padding)
+	}
+	if size := tg.Size(); size != uint32(len(b)) {
+		t.Errorf("expected size %d but got %d", len(b), size)
+	}
+}
+
+func TestDecodePadding(t *testing.T) {
+	b := append([]byte{}, testTag...)
+	b[9] += 100
+	b = append(b, make([]byte, 100)...)
+	b = append(b, 0xFF, 0xFB, 0x90, 0x00)
+
+	tg, err := Decode(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if order := tg.FrameOrder(); len(order) != 1 {
+		t.Errorf("expected frame parsing to stop at the padding but got %v", order)
+	}
+	if p := tg.Padding(); p != 100 {
+		t.Errorf("expected 100 bytes of padding but got %d", p)
+	}
+	if size := tg.Size(); size != uint32(len(testTag)+100) {
+		t.Errorf("expected size %d including the padding but got %d", len(testTag)+100, size)
+	}
+
+	tg.SetFrame("TIT2", []byte("\x00Other"))
+	if p := tg.Padding(); p != 0 {
+		t.Errorf("expected no padding after changing the frames but got %d", p)
+	}
+}
+
+func TestEncodePaddingSize(t *testing.T) {
+	tg := NewTag()
+	tg.SetFrame("TIT2", []byte("\x00Test"))
+	tg.SetFrame("TPE1", []byte("\x00Artist"))
+
+	buf := &bytes.Buffer{}
+	if err := EncodeWithOptions(buf, tg, EncodeOptions{PaddingSize: 100}); err != nil {
+		t.Fatal(err)
+	}
+
+	padded, err := Decode(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := padded.Padding(); p != 100 {
+		t.Errorf("expected 100 bytes of padding but got %d", p)
+	}
+	for id, data := range tg.Frames() {
+		if got := padded.Frames()[id]; !bytes.Equal(got, data) {
+			t.Errorf("expected %s %q but got %q", id, data, got)
+		}
+	}
+	if n := len(padded.FrameOrder()); n != 2 {
+		t.Errorf("expected 2 frames but got %d", n)
+	}
+
+	if err := EncodeWithOptions(&countingWriter{}, tg, EncodeOptions{PaddingSize: maxTagSize}); err == nil {
+		t.Error("expected an error when the padding doesn't fit in the tag size")
+	}
+}
+
+func TestDecodeTruncated(t *testing.T) {
+	for _, n := range []int{len(testTag) - 2, 15} {
+		_, err := Decode(bytes.NewReader(testTag[:n]))
+		if !errors.Is(err, ErrTruncated) {
+			t.Errorf("expected ErrTruncated for %d of %d bytes but got %v", n, len(testTag), err)
+		}
+	}
+}
+
+func TestDecodeResyncFrames(t *testing.T) {
+	// TIT2 declares 5 bytes but is followed by 3 more before TPE1
+	b := []byte{
+		'I', 'D', '3', 3, 0, 0, 0, 0, 0, 33,
+		'T', 'I', 'T', '2', 0, 0, 0, 5, 0, 0,
+		0, 'T', 'e', 's', 't', 'e', 'r', 's',
+		'T', 'P', 'E', '1', 0, 0, 0, 5, 0, 0,
+		0, 'A', 'r', 't', '!',
+	}
+
+	if tg, err := Decode(bytes.NewReader(b)); err == nil {
+		if _, ok := tg.GetFrame("TPE1"); ok {
+			t.Error("expected the misaligned TPE1 to be lost without ResyncFrames")
+		}
+	}
+
+	tg, err := DecodeWithOptions(bytes.NewReader(b), DecodeOptions{ResyncFrames: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"TIT2", "TPE1"}
+	order := tg.FrameOrder()
+	if len(order) != len(expected) || order[0] != expected[0] || order[1] != expected[1] {
+		t.Fatalf("expected frames %v but got %v", expected, order)
+	}
+	if data := tg.Frames()["TPE1"]; !bytes.Equal(data, []byte("\x00Art!")) {
+		t.Errorf("expected TPE1 %q but got %q", "\x00Art!", data)
+	}
+}
+
+func TestDecodeAbsurdFrameSize(t *testing.T) {
+	for _, size := range []uint32{0xFFFFFFFF, 0x7FFFFFFF, 0x0FFFFFFF, 6} {
+		b := append([]byte{}, testTag...)
+		binary.BigEndian.PutUint32(b[14:18], size)
+
+		// Audio following the tag mustn't be read as frame data
+		r := bytes.NewReader(append(b, make([]byte, 1024)...))
+		if _, err := Decode(r); err == nil {
+			t.Errorf("expected an error for frame size 0x%08X", size)
+		}
+		if n := r.Len(); n < 1024 {
+			t.Errorf("expected decoding not to read past the tag for frame size 0x%08X but only %d bytes were left", size, n)
+		}
+	}
+}
+
+func TestHeaderFlags(t *testing.T) {
+	cases := []struct {
+		flags                                        byte
+		unsynchronised, extendedHeader, experimental bool
+	}{
+		{0, false, false, false},
+		{HeaderFlagUnsynchronisation, true, false, false},
+		{HeaderFlagExtendedHeader, false, true, false},
+		{HeaderFlagExperimentalIndicator, false, false, true},
+	}
+
+	for _, c := range cases {
+		b := append([]byte{}, testTag...)
+		if c.flags&HeaderFlagExtendedHeader != 0 {
+			b = append(b[:10], append([]byte{0, 0, 0, 6, 0, 0, 0, 0, 0, 0}, b[10:]...)...)
+			b[9] += 10
+		}
+		b[5] = c.flags
+
+		tg, err := Decode(bytes.NewReader(b))
+		if err != nil {
+			t.Fatalf("flags %02X: %v", c.flags, err)
+		}
+
+		if flags := tg.Flags(); flags != c.flags {
+			t.Errorf("expected flags %02X but got %02X", c.flags, flags)
+		}
+		if v := id3v2.IsUnsynchronised(tg); v != c.unsynchronised {
+			t.Errorf("flags %02X: expected IsUnsynchronised %t but got %t", c.flags, c.unsynchronised, v)
+		}
+		if v := id3v2.HasExtendedHeader(tg); v != c.extendedHeader {
+			t.Errorf("flags %02X: expected HasExtendedHeader %t but got %t", c.flags, c.extendedHeader, v)
+		}
+		if v := id3v2.IsExperimental(tg); v != c.experimental {
+			t.Errorf("flags %02X: expected IsExperimental %t but got %t", c.flags, c.experimental, v)
+		}
+	}
+}
+
+func TestVersion(t *testing.T) {
+	tg, err := Decode(bytes.NewReader(testTag))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if major, revision := tg.Version(); major != 3 || revision != 0 {
+		t.Errorf("expected version 3.0 but got %d.%d", major, revision)
+	}
+
+	if major, revision := NewTag().Version(); major != 3 || revision != 0 {
+		t.Errorf("expected a new tag to be version 3.0 but got %d.%d", major, revision)
+	}
+}
+
+func TestEachFrameDuplicates(t *testing.T) {
+	frames := []struct {
+		id, data string
+	}{
+		{"COMM", "\x00eng\x00First"},
+		{"TXXX", "\x00A\x001"},
+		{"COMM", "\x00eng\x00Second"},
+		{"TIT2", "\x00Test"},
+		{"TXXX", "\x00B\x002"},
+		{"COMM", "\x00deu\x00Third"},
+	}
+
+	tg := NewTag()
+	for _, f := range frames {
+		tg.AddFrame(f.id, []byte(f.data))
+	}
+	buf := &bytes.Buffer{}
+	if err := Encode(buf, tg); err != nil {
+		t.Fatal(err)
+	}
+
+	tg, err := Decode(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	i := 0
+	err = tg.EachFrame(func(id string, data []byte) error {
+		if i < len(frames) && (id != frames[i].id || string(data) != frames[i].data) {
+			t.Errorf("expected frame %d to be %s %q but got %s %q", i, frames[i].id, frames[i].data, id, data)
+		}
+		i++
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if i != len(frames) {
+		t.Errorf("expected %d frames but got %d", len(frames), i)
+	}
+
+	stop := errors.New("stop")
+	i = 0
+	err = tg.EachFrame(func(id string, data []byte) error {
+		i++
+		if id == "TIT2" {
+			return stop
+		}
+		return nil
+	})
+	if err != stop || i != 4 {
+		t.Errorf("expected to stop at the fourth frame with the error but got %d frames and %v", i, err)
+	}
+}
+
+func TestDecodePOPM(t *testing.T) {
+	cases := []struct {
+		name string
+		data []byte
+		p    Popularimeter
+	}{
+		{
+			"Windows Media Player",
+			[]byte("Windows Media Player 9 Series\x00\xC4"),
+			Popularimeter{Email: "Windows Media Player 9 Series", Rating: 196},
+		},
+		{
+			"counter",
+			[]byte("no@email\x00\xFF\x00\x00\x01\x2C"),
+			Popularimeter{Email: "no@email", Rating: 255, Counter: 300},
+		},
+		{
+			"huge counter",
+			[]byte("a@b\x00\x80\x01\x02\x03\x04\x05\x06\x07\x08\x09"),
+			Popularimeter{Email: "a@b", Rating: 128, Counter: math.MaxUint64},
+		},
+	}
+
+	for _, c := range cases {
+		p, err := DecodePOPM(c.data)
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if *p != c.p {
+			t.Errorf("%s: expected %+v but got %+v", c.name, c.p, *p)
+		}
+	}
+
+	if _, err := DecodePOPM([]byte("no rating\x00")); err == nil {
+		t.Error("expected an error without a rating")
+	}
+}
+
+func TestEncodePOPM(t *testing.T) {
+	for _, p := range []Popularimeter{{Email: "user@example.com", Rating: 64}, {Email: "user@example.com", Rating: 1, Counter: 1 << 33}} {
+		data, err := EncodePOPM(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := DecodePOPM(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if *got != p {
+			t.Errorf("expected %+v but got %+v", p, *got)
+		}
+	}
+
+	if data, _ := EncodePOPM(Popularimeter{Email: "u", Rating: 5, Counter: 7}); !bytes.Equal(data, []byte("u\x00\x05\x00\x00\x00\x07")) {
+		t.Errorf("expected a 4 byte counter but got %v", data)
+	}
+	if _, err := EncodePOPM(Popularimeter{Email: "ü@例.com"}); err == nil {
+		t.Error("expected an error for an email that isn't ISO-8859-1")
+	}
+}
+
+func TestDecodePCNT(t *testing.T) {
+	cases := []struct {
+		data  []byte
+		count uint64
+	}{
+		{[]byte{0x00, 0x00, 0x01, 0x00}, 256},
+		{[]byte{0x01, 0x00, 0x00, 0x00, 0x00, 0x02}, 1<<40 + 2},
+		{[]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x05}, 5},
+	}
+
+	for _, c := range cases {
+		n, err := DecodePCNT(c.data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != c.count {
+			t.Errorf("expected count %d from %v but got %d", c.count, c.data, n)
+		}
+	}
+
+	if _, err := DecodePCNT([]byte{0x01, 0, 0, 0, 0, 0, 0, 0, 0}); err == nil {
+		t.Error("expected an error for a counter wider than 8 bytes")
+	}
+	if _, err := DecodePCNT([]byte{0, 1}); err == nil {
+		t.Error("expected an error for a counter shorter than 4 bytes")
+	}
+}
+
+func TestEncodePCNT(t *testing.T) {
+	cases := []struct {
+		count uint64
+		data  []byte
+	}{
+		{0, []byte{0, 0, 0, 0}},
+		{256, []byte{0, 0, 1, 0}},
+		{1<<40 + 2, []byte{0x01, 0, 0, 0, 0, 0x02}},
+		{math.MaxUint64, []byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}},
+	}
+
+	for _, c := range cases {
+		if data := EncodePCNT(c.count); !bytes.Equal(data, c.data) {
+			t.Errorf("expected %d to encode as %v but got %v", c.count, c.data, data)
+		}
+	}
+}
+
+func TestRoundTripUFID(t *testing.T) {
+	const owner = "http://musicbrainz.org"
+	uuid := []byte{
+		0x5b, 0x11, 0xf4, 0xce, 0xa6, 0x2d, 0x47, 0x1e,
+		0x81, 0xfc, 0xa6, 0x9a, 0x82, 0x78, 0xc7, 0xda,
+	}
+
+	tg := NewTag()
+	for _, f := range []struct {
+		owner string
+		id    []byte
+	}{{owner, uuid}, {"http://example.com", []byte("other")}} {
+		data, err := EncodeUFID(f.owner, f.id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tg.AddFrame("UFID", data)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := Encode(buf, tg); err != nil {
+		t.Fatal(err)
+	}
+	tg, err := Decode(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	frames := tg.FramesByID("UFID")
+	if len(frames) != 2 {
+		t.Fatalf("expected 2 UFID frames but got %d", len(frames))
+	}
+	o, id, err := DecodeUFID(frames[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o != owner || !bytes.Equal(id, uuid) {
+		t.Errorf("expected owner %s and identifier %x but got %s and %x", owner, uuid, o, id)
+	}
+
+	if _, err := EncodeUFID(owner, make([]byte, 65)); err == nil {
+		t.Error("expected an error for an identifier longer than 64 bytes")
+	}
+	if _, err := EncodeUFID("", uuid); err == nil {
+		t.Error("expected an error for an empty owner")
+	}
+	if _, _, err := DecodeUFID([]byte("no terminator")); err == nil {
+		t.Error("expected an error for a frame without an owner terminator")
+	}
+}
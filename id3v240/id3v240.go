@@ -0,0 +1,613 @@
+// Implements ID3v2.4.0 described at http://id3.org/id3v2.4.0-structure
+
+package id3v240
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/jlubawy/go-id3v2"
+	"github.com/jlubawy/go-id3v2/internal/framelist"
+)
+
+const VersionString = "id3v2.4.0"
+
+// a - Unsynchronisation
+// Bit 7 in the 'ID3v2 flags' indicates whether or not unsynchronisation is applied on all frames; a set bit indicates usage.
+// b - Extended header
+// The second bit (bit 6) indicates whether or not the header is followed by an extended header.
+// c - Experimental indicator
+// The third bit (bit 5) is used as an 'experimental indicator'. This flag SHALL always be set when the tag is in an experimental stage.
+// d - Footer present
+// Bit 4 indicates that a footer is present at the very end of the tag. A set bit indicates the presence of a footer.
+const (
+	HeaderFlagFooterPresent         = uint8(1 << 4)
+	HeaderFlagExperimentalIndicator = uint8(1 << 5)
+	HeaderFlagExtendedHeader        = uint8(1 << 6)
+	HeaderFlagUnsynchronisation     = uint8(1 << 7)
+)
+
+// b - Tag is an update
+// If this flag is set, the present tag is an update of a tag found earlier in the present file or stream. If frames defined as unique are found in the present tag, they are to override any corresponding ones found in the earlier tag. This flag has no corresponding data.
+const ExtendedHeaderFlagUpdate = uint8(1 << 6)
+
+// updateHeader is the extended header of a tag which updates an earlier one:
+// its size, a single byte of flags with only the update flag set, and the
+// empty data of the update flag.
+var updateHeader = []byte{0, 0, 0, 7, 1, ExtendedHeaderFlagUpdate, 0}
+
+// ID3v2/file identifier   "ID3"
+// ID3v2 version           $04 00
+// ID3v2 flags             %abcd0000
+// ID3v2 size              4 * %0xxxxxxx
+//
+// The footer is a copy of the header with the identifier "3DI".
+type header struct {
+	ID        [3]byte
+	Version   [2]byte
+	Flags     byte
+	SynchSafe uint32
+}
+
+// FooterIdentifier identifies the footer which may follow an ID3v2.4.0 tag.
+var FooterIdentifier = []byte("3DI")
+
+// a - Tag alter preservation
+// b - File alter preservation
+// c - Read only
+// h - Grouping identity
+// k - Compression
+// m - Encryption
+// n - Unsynchronisation
+// p - Data length indicator
+const (
+	FrameFlagDataLengthIndicator   = uint16(1 << 0)
+	FrameFlagUnsynchronisation     = uint16(1 << 1)
+	FrameFlagEncryption            = uint16(1 << 2)
+	FrameFlagCompression           = uint16(1 << 3)
+	FrameFlagGroupingIdentity      = uint16(1 << 6)
+	FrameFlagReadOnly              = uint16(1 << 12)
+	FrameFlagFileAlterPreservation = uint16(1 << 13)
+	FrameFlagTagAlterPreservation  = uint16(1 << 14)
+)
+
+// Frame ID      $xx xx xx xx  (four characters)
+// Size      4 * %0xxxxxxx
+// Flags         $xx xx (%0abc0000 %0h00kmnp)
+type frame struct {
+	ID        [4]byte
+	SynchSafe uint32
+	Flags     uint16
+}
+
+type tag struct {
+	header
+
+	// extendedHeader holds the extended header as it was decoded, after its
+	// size. It isn't written on encode since its CRC and restrictions may no
+	// longer hold.
+	extendedHeader []byte
+
+	framelist.List
+}
+
+// format describes the frame headers of ID3v2.4.0.
+var format = framelist.Format{
+	HeaderSize:      binary.Size(frame{}),
+	StatusFlags:     FrameFlagTagAlterPreservation | FrameFlagFileAlterPreservation | FrameFlagReadOnly,
+	ExtraHeaderSize: extraHeaderSize,
+}
+
+// Flatten returns the frames as human-readable key/value pairs.
+func (t *tag) Flatten() []id3v2.KV {
+	return id3v2.Flatten(t, SupportedFrames)
+}
+
+// Size returns the size of the tag as decoded, including the header, any
+// padding and the footer, or once the frames change the size they take up
+// encoded with the header and footer.
+func (t *tag) Size() uint32 {
+	size := id3v2.SynchSafeToSize(t.SynchSafe) + uint32(binary.Size(t.header))
+	if t.Changed() {
+		size = uint32(binary.Size(t.header)) + t.FramesSize()
+	}
+	if t.header.Flags&HeaderFlagFooterPresent != 0 {
+		size += uint32(binary.Size(t.header))
+	}
+	return size
+}
+
+// EncodeLosses describes what Encode doesn't keep of the tag as decoded,
+// besides padding.
+func (t *tag) EncodeLosses() []string {
+	var losses []string
+	if t.header.Flags&HeaderFlagUnsynchronisation != 0 {
+		losses = append(losses, "unsynchronisation is not preserved")
+	}
+	if len(t.extendedHeader) > 0 {
+		losses = append(losses, "extended header is not preserved")
+	}
+	if t.header.Flags&HeaderFlagExperimentalIndicator != 0 {
+		losses = append(losses, "experimental indicator is not preserved")
+	}
+	return losses
+}
+
+// IsUpdate returns true if the extended header of the tag marks it as an
+// update of an earlier tag, whose unique frames it replaces.
+func (t *tag) IsUpdate() bool {
+	ext := t.extendedHeader
+	return len(ext) >= 2 && ext[0] >= 1 && ext[1]&ExtendedHeaderFlagUpdate != 0
+}
+
+// Version returns the major version and revision of the tag, e.g. 3 and 0
+// for ID3v2.3.0.
+func (t *tag) Version() (major, revision byte) {
+	return t.header.Version[0], t.header.Version[1]
+}
+
+// Flags returns the header flags of the tag as decoded.
+func (t *tag) Flags() byte {
+	return t.header.Flags
+}
+
+// NewTag returns an empty ID3v2.4.0 tag.
+func NewTag() id3v2.Tag {
+	t := &tag{
+		header: header{
+			Version: [2]byte{4, 0},
+		},
+		List: framelist.New(format, nil, 0),
+	}
+	copy(t.header.ID[:], id3v2.FileIdentifier)
+
+	return id3v2.Tag(t)
+}
+
+func Decode(r io.Reader) (id3v2.Tag, error) {
+	t := &tag{}
+
+	if err := binary.Read(r, binary.BigEndian, &t.header); err != nil {
+		return nil, err
+	}
+
+	if !bytes.Equal(t.header.ID[:], id3v2.FileIdentifier) {
+		return nil, id3v2.ErrFormat
+	}
+
+	bytesLeft := id3v2.SynchSafeToSize(t.header.SynchSafe)
+
+	// The extended header size is synchsafe and includes the size itself
+	if t.header.Flags&HeaderFlagExtendedHeader != 0 {
+		var synchSafe uint32
+		if err := binary.Read(r, binary.BigEndian, &synchSafe); err != nil {
+			return nil, err
+		}
+
+		size := id3v2.SynchSafeToSize(synchSafe)
+		if size < 4 || size > bytesLeft {
+			return nil, fmt.Errorf("id3v240: extended header size %d exceeds the %d bytes left in the tag", size, bytesLeft)
+		}
+
+		t.extendedHeader = make([]byte, size-4)
+		if _, err := io.ReadFull(r, t.extendedHeader); err != nil {
+			return nil, err
+		}
+
+		bytesLeft = bytesLeft - size
+	}
+
+	// Every frame consumes at least its header so a tag can't hold more
+	// frames than this, cap the loop in case the arithmetic ever goes wrong
+	maxFrames := bytesLeft/uint32(binary.Size(frame{})) + 1
+
+	var frames []id3v2.Frame
+	var padding uint32
+
+	for n := uint32(0); bytesLeft > 0; n++ {
+		f := frame{}
+
+		if n >= maxFrames {
+			return nil, fmt.Errorf("id3v240: expected at most %d frames in the tag", maxFrames)
+		}
+
+		// Anything too small to hold a frame header can only be padding
+		if bytesLeft < uint32(binary.Size(f)) {
+			padding = bytesLeft
+			break
+		}
+
+		if err := binary.Read(r, binary.BigEndian, &f); err != nil {
+			return nil, err
+		}
+
+		bytesLeft = bytesLeft - uint32(binary.Size(f))
+
+		if f.ID[0] == 0 {
+			padding = bytesLeft + uint32(binary.Size(f))
+			break
+		}
+
+		// Unlike ID3v2.3.0 the frame size is synchsafe, a size with the top
+		// bit of any byte set was written by a 2.3 encoder
+		if !isSynchSafe(f.SynchSafe) {
+			return nil, fmt.Errorf("id3v240: frame '%s' size 0x%08X is not synchsafe", f.ID[:], f.SynchSafe)
+		}
+		size := id3v2.SynchSafeToSize(f.SynchSafe)
+
+		// Refuse frames that claim more data than the tag holds before
+		// allocating anything for them
+		if size > bytesLeft {
+			return nil, fmt.Errorf("id3v240: frame '%s' size %d exceeds the %d bytes left in the tag", f.ID[:], size, bytesLeft)
+		}
+
+		data := make([]byte, size)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, err
+		}
+
+		bytesLeft = bytesLeft - size
+
+		fe := id3v2.Frame{
+			ID:    string(f.ID[:]),
+			Flags: f.Flags,
+			Data:  data,
+		}
+
+		// Keep the data the flags add to the frame header apart from the
+		// frame's own data
+		if n := extraHeaderSize(f.Flags); n > 0 && len(fe.Data) >= n {
+			fe.ExtraHeader = fe.Data[:n]
+			fe.Data = fe.Data[n:]
+		}
+
+		// Unsynchronisation is applied to each frame's data after the frame
+		// header and the data its flags add. The header flag means every
+		// frame is unsynchronised whether or not its own flag is set, but
+		// it's only ever undone once.
+		if fe.Flags&FrameFlagUnsynchronisation != 0 || t.header.Flags&HeaderFlagUnsynchronisation != 0 {
+			var err error
+			if fe.Data, err = ioutil.ReadAll(id3v2.NewUnsyncReader(bytes.NewReader(fe.Data))); err != nil {
+				return nil, err
+			}
+			fe.Flags &^= FrameFlagUnsynchronisation
+		}
+
+		if alias, ok := frameAliases[fe.ID]; ok {
+			fe.ID = alias
+		}
+
+		if _, ok := SupportedFrames[fe.ID]; !ok {
+			fe.Raw = true
+		}
+
+		frames = append(frames, fe)
+	}
+
+	t.List = framelist.New(format, frames, padding)
+
+	if t.header.Flags&HeaderFlagFooterPresent != 0 {
+		footer := header{}
+		if err := binary.Read(r, binary.BigEndian, &footer); err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(footer.ID[:], FooterIdentifier) {
+			return nil, fmt.Errorf("id3v240: expected footer identifier '%s' but got '%s'", FooterIdentifier, footer.ID[:])
+		}
+	}
+
+	return id3v2.Tag(t), nil
+}
+
+// isSynchSafe returns true if none of the bytes of s have their top bit set.
+func isSynchSafe(s uint32) bool {
+	return s&0x80808080 == 0
+}
+
+// hasFooter returns true if a tag was decoded with a footer.
+func hasFooter(tg id3v2.Tag) bool {
+	t, ok := tg.(*tag)
+	return ok && t.header.Flags&HeaderFlagFooterPresent != 0
+}
+
+// extraHeaderSize returns the number of bytes the frame flags add to the
+// frame header, which are counted in the frame size.
+//
+// Group identifier       $xx           (grouping identity)
+// Encryption method      $xx           (encryption)
+// Data length indicator  4 * %0xxxxxxx (data length indicator)
+func extraHeaderSize(flags uint16) int {
+	n := 0
+	if flags&FrameFlagGroupingIdentity != 0 {
+		n++
+	}
+	if flags&FrameFlagEncryption != 0 {
+		n++
+	}
+	if flags&FrameFlagDataLengthIndicator != 0 {
+		n += 4
+	}
+	return n
+}
+
+// frameEntries returns every frame of a tag in order.
+func frameEntries(tg id3v2.Tag) []id3v2.Frame {
+	if t, ok := tg.(*tag); ok {
+		return t.Entries()
+	}
+	return framelist.Of(tg)
+}
+
+func Encode(w io.Writer, tag id3v2.Tag) error {
+	frames, err := encodeFrames(tag)
+	if err != nil {
+		return err
+	}
+
+	body := bytes.Join(frames, nil)
+	if len(body) > maxSize {
+		return fmt.Errorf("id3v240: tag size %d exceeds the maximum of %d", len(body), maxSize)
+	}
+
+	return writeTag(w, headerFlags(tag), nil, body)
+}
+
+// EncodeSplit encodes tag as consecutive ID3v2.4.0 tags of at most limit bytes
+// each, including their headers and footers, for content that doesn't fit in
+// a single tag. Every tag after the first is marked as an update in its
+// extended header so that id3v2.DecodeAll merges them back together. Frames
+// aren't split, so each must fit in a tag on its own.
+func EncodeSplit(w io.Writer, tag id3v2.Tag, limit uint32) error {
+	frames, err := encodeFrames(tag)
+	if err != nil {
+		return err
+	}
+
+	flags := headerFlags(tag)
+	overhead := binary.Size(header{})
+	if flags&HeaderFlagFooterPresent != 0 {
+		overhead += binary.Size(header{})
+	}
+	if int64(limit) < int64(overhead+len(updateHeader)) {
+		return fmt.Errorf("id3v240: tag size limit %d is too small to hold an update tag", limit)
+	}
+
+	var ext []byte
+	for i := 0; ; ext = updateHeader {
+		room := int64(limit) - int64(overhead+len(ext))
+		if room > maxSize {
+			room = maxSize
+		}
+
+		body := &bytes.Buffer{}
+		for ; i < len(frames) && int64(body.Len()+len(frames[i])) <= room; i++ {
+			body.Write(frames[i])
+		}
+		if body.Len() == 0 && i < len(frames) {
+			return fmt.Errorf("id3v240: frame '%s' size %d doesn't fit in a tag of %d bytes", frames[i][:4], len(frames[i]), limit)
+		}
+
+		if err := writeTag(w, flags, ext, body.Bytes()); err != nil {
+			return err
+		}
+		if i == len(frames) {
+			return nil
+		}
+	}
+}
+
+// encodeFrames encodes every frame of a tag, each with its header.
+func encodeFrames(tag id3v2.Tag) ([][]byte, error) {
+	var frames [][]byte
+
+	for _, fe := range frameEntries(tag) {
+		if len(fe.ID) != 4 {
+			return nil, fmt.Errorf("id3v240: expected frame ID of length 4 but got %d", len(fe.ID))
+		}
+		if !isSupported(fe.ID) && !fe.Raw {
+			return nil, fmt.Errorf("id3v240: unsupported frame ID '%s'", fe.ID)
+		}
+
+		// Drop the extra header bytes if the flags no longer call for them
+		extra := fe.ExtraHeader
+		if len(extra) != extraHeaderSize(fe.Flags) {
+			extra = nil
+		}
+
+		size := uint32(len(extra) + len(fe.Data))
+		if size > maxSize {
+			return nil, fmt.Errorf("id3v240: frame '%s' size %d exceeds the maximum of %d", fe.ID, size, maxSize)
+		}
+
+		f := frame{
+			SynchSafe: id3v2.SizeToSynchSafe(size),
+			Flags:     fe.Flags &^ FrameFlagUnsynchronisation,
+		}
+		copy(f.ID[:], []byte(fe.ID))
+
+		fBuf := &bytes.Buffer{}
+		if err := binary.Write(fBuf, binary.BigEndian, f); err != nil {
+			return nil, err
+		}
+		fBuf.Write(extra)
+		fBuf.Write(fe.Data)
+
+		frames = append(frames, fBuf.Bytes())
+	}
+
+	return frames, nil
+}
+
+// headerFlags returns the header flags to encode a tag with.
+func headerFlags(tag id3v2.Tag) byte {
+	// Keep the footer of tags decoded with one
+	if hasFooter(tag) {
+		return HeaderFlagFooterPresent
+	}
+	return 0
+}
+
+// writeTag writes a tag holding the encoded frames, preceded by the extended
+// header ext if it isn't empty.
+func writeTag(w io.Writer, flags byte, ext, frames []byte) error {
+	h := header{
+		Version: [2]byte{4, 0},
+		Flags:   flags,
+	}
+	copy(h.ID[:], id3v2.FileIdentifier)
+
+	if len(ext) > 0 {
+		h.Flags |= HeaderFlagExtendedHeader
+	}
+
+	h.SynchSafe = id3v2.SizeToSynchSafe(uint32(len(ext) + len(frames)))
+
+	if err := binary.Write(w, binary.BigEndian, h); err != nil {
+		return err
+	}
+
+	if _, err := w.Write(ext); err != nil {
+		return err
+	}
+
+	if _, err := w.Write(frames); err != nil {
+		return err
+	}
+
+	if h.Flags&HeaderFlagFooterPresent != 0 {
+		copy(h.ID[:], FooterIdentifier)
+		if err := binary.Write(w, binary.BigEndian, h); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// The largest size a synchsafe integer can hold.
+const maxSize = 0x0FFFFFFF
+
+func init() {
+	id3v2.RegisterVersionInfo(id3v2.VersionInfo{
+		Major:    4,
+		Revision: 0,
+		Decode:   Decode,
+		Encode:   Encode,
+		NewTag:   NewTag,
+	})
+}
+
+// TolerantFrames is a map of non-standard frames written by tools in the
+// wild, and their descriptions. Encode accepts them alongside SupportedFrames.
+var TolerantFrames = map[string]string{
+	"GRP1": "Grouping, written by iTunes",
+	"MVIN": "Movement number/count, written by iTunes",
+	"MVNM": "Movement name, written by iTunes",
+	"NCON": "MusicMatch binary data",
+	"XSOP": "Performer sort order, written by early versions of iTunes",
+}
+
+// frameAliases maps non-standard frame IDs to the standard frame they're
+// decoded as.
+var frameAliases = map[string]string{
+	"XSOP": "TSOP",
+}
+
+// isSupported returns true if a frame ID may be encoded.
+func isSupported(id string) bool {
+	if _, ok := SupportedFrames[id]; ok {
+		return true
+	}
+	_, ok := TolerantFrames[id]
+	return ok
+}
+
+// SupportedFrames is a map of frames supported by ID3v2.4.0 and their descriptions.
+var SupportedFrames = map[string]string{
+	"AENC": "[#sec4.19 Audio encryption]",
+	"APIC": "[#sec4.14 Attached picture]",
+	"ASPI": "[#sec4.30 Audio seek point index]",
+	"COMM": "[#sec4.10 Comments]",
+	"COMR": "[#sec4.24 Commercial frame]",
+	"ENCR": "[#sec4.25 Encryption method registration]",
+	"EQU2": "[#sec4.12 Equalisation (2)]",
+	"ETCO": "[#sec4.5 Event timing codes]",
+	"GEOB": "[#sec4.15 General encapsulated object]",
+	"GRID": "[#sec4.26 Group identification registration]",
+	"LINK": "[#sec4.20 Linked information]",
+	"MCDI": "[#sec4.4 Music CD identifier]",
+	"MLLT": "[#sec4.6 MPEG location lookup table]",
+	"OWNE": "[#sec4.23 Ownership frame]",
+	"PRIV": "[#sec4.27 Private frame]",
+	"PCNT": "[#sec4.16 Play counter]",
+	"POPM": "[#sec4.17 Popularimeter]",
+	"POSS": "[#sec4.21 Position synchronisation frame]",
+	"RBUF": "[#sec4.18 Recommended buffer size]",
+	"RVA2": "[#sec4.11 Relative volume adjustment (2)]",
+	"RVRB": "[#sec4.13 Reverb]",
+	"SEEK": "[#sec4.29 Seek frame]",
+	"SIGN": "[#sec4.28 Signature frame]",
+	"SYLT": "[#sec4.9 Synchronised lyric/text]",
+	"SYTC": "[#sec4.7 Synchronised tempo codes]",
+	"TALB": "[#TALB Album/Movie/Show title]",
+	"TBPM": "[#TBPM BPM (beats per minute)]",
+	"TCOM": "[#TCOM Composer]",
+	"TCON": "[#TCON Content type]",
+	"TCOP": "[#TCOP Copyright message]",
+	"TDEN": "[#TDEN Encoding time]",
+	"TDLY": "[#TDLY Playlist delay]",
+	"TDOR": "[#TDOR Original release time]",
+	"TDRC": "[#TDRC Recording time]",
+	"TDRL": "[#TDRL Release time]",
+	"TDTG": "[#TDTG Tagging time]",
+	"TENC": "[#TENC Encoded by]",
+	"TEXT": "[#TEXT Lyricist/Text writer]",
+	"TFLT": "[#TFLT File type]",
+	"TIPL": "[#TIPL Involved people list]",
+	"TIT1": "[#TIT1 Content group description]",
+	"TIT2": "[#TIT2 Title/songname/content description]",
+	"TIT3": "[#TIT3 Subtitle/Description refinement]",
+	"TKEY": "[#TKEY Initial key]",
+	"TLAN": "[#TLAN Language(s)]",
+	"TLEN": "[#TLEN Length]",
+	"TMCL": "[#TMCL Musician credits list]",
+	"TMED": "[#TMED Media type]",
+	"TMOO": "[#TMOO Mood]",
+	"TOAL": "[#TOAL Original album/movie/show title]",
+	"TOFN": "[#TOFN Original filename]",
+	"TOLY": "[#TOLY Original lyricist(s)/text writer(s)]",
+	"TOPE": "[#TOPE Original artist(s)/performer(s)]",
+	"TOWN": "[#TOWN File owner/licensee]",
+	"TPE1": "[#TPE1 Lead performer(s)/Soloist(s)]",
+	"TPE2": "[#TPE2 Band/orchestra/accompaniment]",
+	"TPE3": "[#TPE3 Conductor/performer refinement]",
+	"TPE4": "[#TPE4 Interpreted, remixed, or otherwise modified by]",
+	"TPOS": "[#TPOS Part of a set]",
+	"TPRO": "[#TPRO Produced notice]",
+	"TPUB": "[#TPUB Publisher]",
+	"TRCK": "[#TRCK Track number/Position in set]",
+	"TRSN": "[#TRSN Internet radio station name]",
+	"TRSO": "[#TRSO Internet radio station owner]",
+	"TSOA": "[#TSOA Album sort order]",
+	"TSOP": "[#TSOP Performer sort order]",
+	"TSOT": "[#TSOT Title sort order]",
+	"TSRC": "[#TSRC ISRC (international standard recording code)]",
+	"TSSE": "[#TSSE Software/Hardware and settings used for encoding]",
+	"TSST": "[#TSST Set subtitle]",
+	"TXXX": "[#TXXX User defined text information frame]",
+	"UFID": "[#sec4.1 Unique file identifier]",
+	"USER": "[#sec4.22 Terms of use]",
+	"USLT": "[#sec4.8 Unsynchronised lyric/text transcription]",
+	"WCOM": "[#WCOM Commercial information]",
+	"WCOP": "[#WCOP Copyright/Legal information]",
+	"WOAF": "[#WOAF Official audio file webpage]",
+	"WOAR": "[#WOAR Official artist/performer webpage]",
+	"WOAS": "[#WOAS Official audio source webpage]",
+	"WORS": "[#WORS Official Internet radio station homepage]",
+	"WPAY": "[#WPAY Payment]",
+	"WPUB": "[#WPUB Publishers official webpage]",
+	"WXXX": "[#WXXX User defined URL link frame]",
+}
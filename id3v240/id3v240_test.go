@@ -0,0 +1,239 @@
+package id3v240
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/jlubawy/go-id3v2"
+	_ "github.com/jlubawy/go-id3v2/id3v230"
+)
+
+var testTag = []byte{
+	'I', 'D', '3', 4, 0, 0, 0, 0, 0, 30,
+	'T', 'I', 'T', '2', 0, 0, 0, 5, 0, 0,
+	3, 'T', 'e', 's', 't',
+	'T', 'D', 'R', 'C', 0, 0, 0, 5, 0, 0,
+	3, '2', '0', '1', '6',
+}
+
+func TestRoundTrip(t *testing.T) {
+	tg, ver, err := id3v2.Decode(bytes.NewReader(testTag))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ver != VersionString {
+		t.Errorf("expected version %s but got %s", VersionString, ver)
+	}
+	if s := string(tg.Frames()["TDRC"]); s != "\x032016" {
+		t.Errorf("expected TDRC '\\x032016' but got %q", s)
+	}
+	if major, revision := tg.Version(); major != 4 || revision != 0 {
+		t.Errorf("expected version 4.0 but got %d.%d", major, revision)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := Encode(buf, tg); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), testTag) {
+		t.Errorf("expected %v but got %v", testTag, buf.Bytes())
+	}
+}
+
+func TestRoundTripFooter(t *testing.T) {
+	b := append([]byte{}, testTag...)
+	b[5] = HeaderFlagFooterPresent
+	b = append(b, '3', 'D', 'I', 4, 0, HeaderFlagFooterPresent, 0, 0, 0, 30)
+
+	tg, err := Decode(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size := tg.Size(); size != uint32(len(b)) {
+		t.Errorf("expected size %d but got %d", len(b), size)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := Encode(buf, tg); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), b) {
+		t.Errorf("expected %v but got %v", b, buf.Bytes())
+	}
+
+	b[len(b)-10] = 'X'
+	if _, err := Decode(bytes.NewReader(b)); err == nil {
+		t.Error("expected an error for a bad footer identifier")
+	}
+}
+
+// A frame of 255 bytes has the size 0x000000FF in ID3v2.3.0 but 0x0000017F in
+// ID3v2.4.0 where it's synchsafe.
+func TestDecodeSynchSafeFrameSize(t *testing.T) {
+	data := append([]byte{0}, bytes.Repeat([]byte{'a'}, 254)...)
+
+	newTag := func(major byte, size []byte) []byte {
+		b := []byte{'I', 'D', '3', major, 0, 0, 0, 0, 0x02, 0x09}
+		b = append(b, 'T', 'I', 'T', '2')
+		b = append(b, size...)
+		b = append(b, 0, 0)
+		return append(b, data...)
+	}
+
+	tg, _, err := id3v2.Decode(bytes.NewReader(newTag(3, []byte{0, 0, 0, 0xFF})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(tg.Frames()["TIT2"]); n != 255 {
+		t.Errorf("expected ID3v2.3.0 TIT2 of 255 bytes but got %d", n)
+	}
+
+	tg, _, err = id3v2.Decode(bytes.NewReader(newTag(4, []byte{0, 0, 0x01, 0x7F})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(tg.Frames()["TIT2"]); n != 255 {
+		t.Errorf("expected ID3v2.4.0 TIT2 of 255 bytes but got %d", n)
+	}
+
+	if _, _, err := id3v2.Decode(bytes.NewReader(newTag(4, []byte{0, 0, 0, 0xFF}))); err == nil {
+		t.Error("expected an error for an ID3v2.4.0 frame size that isn't synchsafe")
+	}
+
+	buf := &bytes.Buffer{}
+	if err := Encode(buf, tg); err != nil {
+		t.Fatal(err)
+	}
+	if size := buf.Bytes()[14:18]; !bytes.Equal(size, []byte{0, 0, 0x01, 0x7F}) {
+		t.Errorf("expected encoded frame size [0 0 1 127] but got %v", size)
+	}
+}
+
+func TestDecodeFrameUnsynchronisation(t *testing.T) {
+	b := []byte{
+		'I', 'D', '3', 4, 0, 0, 0, 0, 0, 14,
+		'P', 'R', 'I', 'V', 0, 0, 0, 4, 0, byte(FrameFlagUnsynchronisation),
+		0xFF, 0x00, 0xE0, 'a',
+	}
+
+	tg, err := Decode(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data := tg.Frames()["PRIV"]; !bytes.Equal(data, []byte{0xFF, 0xE0, 'a'}) {
+		t.Errorf("expected PRIV [255 224 97] but got %v", data)
+	}
+}
+
+func TestDecodeTagUnsynchronisation(t *testing.T) {
+	flags := FrameFlagUnsynchronisation | FrameFlagDataLengthIndicator
+
+	b := []byte{
+		'I', 'D', '3', 4, 0, HeaderFlagUnsynchronisation, 0, 0, 0, 32,
+		'P', 'R', 'I', 'V', 0, 0, 0, 8, byte(flags >> 8), byte(flags),
+		0, 0, 0, 3, 0xFF, 0x00, 0xE0, 'a',
+		'T', 'I', 'T', '2', 0, 0, 0, 4, 0, 0,
+		0, 0xFF, 0x00, 'b',
+	}
+
+	tg, err := Decode(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Both flags set is only undone once
+	if data := tg.Frames()["PRIV"]; !bytes.Equal(data, []byte{0xFF, 0xE0, 'a'}) {
+		t.Errorf("expected PRIV [255 224 97] but got %v", data)
+	}
+	if flags := tg.FrameFlags("PRIV"); flags != FrameFlagDataLengthIndicator {
+		t.Errorf("expected PRIV flags 0x%04X but got 0x%04X", FrameFlagDataLengthIndicator, flags)
+	}
+
+	// The header flag applies to frames without their own flag
+	if data := tg.Frames()["TIT2"]; !bytes.Equal(data, []byte{0, 0xFF, 'b'}) {
+		t.Errorf("expected TIT2 [0 255 98] but got %v", data)
+	}
+}
+
+func TestSetFrameClearsFormatFlags(t *testing.T) {
+	flags := FrameFlagReadOnly | FrameFlagGroupingIdentity | FrameFlagDataLengthIndicator
+	b := []byte{
+		'I', 'D', '3', 4, 0, 0, 0, 0, 0, 20,
+		'T', 'I', 'T', '2', 0, 0, 0, 10, byte(flags >> 8), byte(flags),
+		0x01, 0, 0, 0, 5, 3, 'T', 'e', 's', 't',
+	}
+
+	tg, err := Decode(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The group and data length no longer hold for the new data
+	tg.SetFrame("TIT2", []byte("\x03Title"))
+
+	expected := []byte{
+		'I', 'D', '3', 4, 0, 0, 0, 0, 0, 16,
+		'T', 'I', 'T', '2', 0, 0, 0, 6, byte(FrameFlagReadOnly >> 8), 0,
+		3, 'T', 'i', 't', 'l', 'e',
+	}
+
+	buf := &bytes.Buffer{}
+	if err := Encode(buf, tg); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), expected) {
+		t.Errorf("expected %v but got %v", expected, buf.Bytes())
+	}
+}
+
+func TestEncodeSplit(t *testing.T) {
+	tg := NewTag()
+	tg.AddFrame("TIT2", []byte("\x03Title"))
+	tg.AddFrame("TPE1", []byte("\x03Artist"))
+	tg.AddFrame("APIC", []byte("\x03image/png\x00\x03\x00front"))
+	tg.AddFrame("APIC", []byte("\x03image/png\x00\x04\x00back"))
+
+	const limit = 50
+
+	buf := &bytes.Buffer{}
+	if err := EncodeSplit(buf, tg, limit); err != nil {
+		t.Fatal(err)
+	}
+
+	next := id3v2.Scan(bytes.NewReader(buf.Bytes()))
+	n := 0
+	for {
+		part, _, err := next()
+		if err != nil {
+			break
+		}
+		if size := part.Size(); size > limit {
+			t.Errorf("expected tag %d to be at most %d bytes but got %d", n, limit, size)
+		}
+		if u := part.(*tag).IsUpdate(); u != (n > 0) {
+			t.Errorf("expected tag %d update flag %t but got %t", n, n > 0, u)
+		}
+		n++
+	}
+	if n < 2 {
+		t.Errorf("expected the frames to be split over several tags but got %d", n)
+	}
+
+	decoded, _, err := id3v2.DecodeAll(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if order, expected := decoded.FrameOrder(), tg.FrameOrder(); !reflect.DeepEqual(order, expected) {
+		t.Errorf("expected frame order %v but got %v", expected, order)
+	}
+	for i := range tg.FrameOrder() {
+		if f, expected := decoded.FrameAt(i), tg.FrameAt(i); !bytes.Equal(f.Data, expected.Data) {
+			t.Errorf("expected %s frame %q but got %q", expected.ID, expected.Data, f.Data)
+		}
+	}
+
+	if err := EncodeSplit(&bytes.Buffer{}, tg, 30); err == nil {
+		t.Error("expected an error for a frame which doesn't fit in a tag")
+	}
+}
@@ -1,6 +1,9 @@
 package id3v2
 
 import (
+	"bytes"
+	"errors"
+	"io"
 	"testing"
 )
 
@@ -16,3 +19,94 @@ func TestSynchSafeSize(t *testing.T) {
 		t.Errorf("expected SizeToSynchSafe(0x%08X) to equal 0x%08X, but got 0x%08X", size, synchSafe, ss)
 	}
 }
+
+func TestAudioOffset(t *testing.T) {
+	tag := []byte{
+		'I', 'D', '3', 3, 0, 0, 0, 0, 0x01, 0x00,
+	}
+	audio := []byte{0xFF, 0xFB, 0x90, 0x00}
+
+	b := append(append(tag, make([]byte, 128)...), audio...)
+
+	offset, err := AudioOffset(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if offset != 10+128 {
+		t.Errorf("expected audio offset %d but got %d", 10+128, offset)
+	}
+
+	offset, err = AudioOffset(bytes.NewReader(append(append([]byte{}, utf8BOM...), b...)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if offset != 3+10+128 {
+		t.Errorf("expected audio offset %d after a BOM but got %d", 3+10+128, offset)
+	}
+
+	offset, err = AudioOffset(bytes.NewReader(audio))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if offset != 0 {
+		t.Errorf("expected audio offset 0 for an untagged file but got %d", offset)
+	}
+}
+
+func TestTagSize(t *testing.T) {
+	cases := []struct {
+		header [10]byte
+		size   uint32
+		ok     bool
+	}{
+		{[10]byte{'I', 'D', '3', 3, 0, 0, 0, 0, 0x01, 0x00}, 10 + 128, true},
+		{[10]byte{'I', 'D', '3', 4, 0, 0x10, 0, 0, 0x01, 0x00}, 10 + 128 + 10, true},
+		{[10]byte{'I', 'D', '3', 3, 0, 0, 0, 0, 0x80, 0x00}, 0, false},
+		{[10]byte{'T', 'A', 'G', 3, 0, 0, 0, 0, 0x01, 0x00}, 0, false},
+	}
+
+	for _, c := range cases {
+		if size, ok := TagSize(c.header); size != c.size || ok != c.ok {
+			t.Errorf("expected TagSize(%v) to equal %d, %t but got %d, %t", c.header, c.size, c.ok, size, ok)
+		}
+	}
+}
+
+func TestNoVersionsRegistered(t *testing.T) {
+	registered := versions
+	versions = nil
+	defer func() { versions = registered }()
+
+	b := []byte{'I', 'D', '3', 3, 0, 0, 0, 0, 0, 0}
+	if _, _, err := Decode(bytes.NewReader(b)); err != ErrNoVersionsRegistered {
+		t.Errorf("expected ErrNoVersionsRegistered from Decode but got %v", err)
+	}
+	if _, err := NewTag("id3v2.3.0"); err != ErrNoVersionsRegistered {
+		t.Errorf("expected ErrNoVersionsRegistered from NewTag but got %v", err)
+	}
+
+	versions = []version{{major: 4, revision: 0}}
+	if _, _, err := Decode(bytes.NewReader(b)); err != ErrVersion {
+		t.Errorf("expected ErrVersion for an unregistered version but got %v", err)
+	}
+}
+
+func TestRegisterVersionDecodeOnly(t *testing.T) {
+	registered := versions
+	versions = nil
+	defer func() { versions = registered }()
+
+	errDecoded := errors.New("decoded")
+	RegisterVersion(3, 0, func(io.Reader) (Tag, error) { return nil, errDecoded })
+
+	b := []byte{'I', 'D', '3', 3, 0, 0, 0, 0, 0, 0}
+	if _, _, err := Decode(bytes.NewReader(b)); err != errDecoded {
+		t.Errorf("expected the registered decoder to be used but got %v", err)
+	}
+	if _, err := NewTag("id3v2.3.0"); err == nil {
+		t.Error("expected an error creating a tag of a version without an encoder")
+	}
+	if tag := MinimalTag("Title", "Artist"); tag != nil {
+		t.Errorf("expected no tag without a version to encode but got %v", tag)
+	}
+}
@@ -0,0 +1,16 @@
+// Registers every ID3v2 version supported by go-id3v2 with a single import.
+//
+//	import _ "github.com/jlubawy/go-id3v2/id3v2all"
+
+package id3v2all
+
+import (
+	_ "github.com/jlubawy/go-id3v2/id3v220"
+	_ "github.com/jlubawy/go-id3v2/id3v230"
+	_ "github.com/jlubawy/go-id3v2/id3v240"
+)
+
+// RegisterAll ensures every supported version is registered with id3v2. The
+// versions register themselves when imported so calling it is only needed
+// when the package would otherwise be unused.
+func RegisterAll() {}
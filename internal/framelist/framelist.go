@@ -0,0 +1,352 @@
+// Package framelist implements the list of frames shared by the tags of
+// every ID3v2 version.
+package framelist
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+
+	"github.com/jlubawy/go-id3v2"
+)
+
+// A Format describes the frame headers of an ID3v2 version.
+type Format struct {
+	// HeaderSize is the size of a frame header.
+	HeaderSize int
+
+	// StatusFlags are the frame flags saying how to treat a frame rather
+	// than how its data is stored, which are kept when its data is
+	// replaced. The others, like compression, no longer hold for new data.
+	StatusFlags uint16
+
+	// ExtraHeaderSize returns the number of bytes the frame flags add to
+	// the frame header. It's nil if frames have no flags.
+	ExtraHeaderSize func(flags uint16) int
+}
+
+// A List holds the frames of a tag. Frames are kept in a list rather than a
+// map since many frames, like COMM and APIC, may appear several times.
+type List struct {
+	format  Format
+	frames  []id3v2.Frame
+	padding uint32
+	changed bool
+
+	// index maps the canonical upper case ID of each frame to the position
+	// of the first frame with it, built by GetFrame and reset when the
+	// frames change
+	index map[string]int
+}
+
+// New returns a list of the frames of a tag as decoded, followed by padding
+// bytes of padding.
+func New(format Format, frames []id3v2.Frame, padding uint32) List {
+	return List{format: format, frames: frames, padding: padding}
+}
+
+// Frames returns the data of each frame by ID. Only the first of any frames
+// sharing an ID is included.
+func (l *List) Frames() map[string][]byte {
+	m := make(map[string][]byte)
+	for _, f := range l.frames {
+		if _, ok := m[f.ID]; !ok {
+			m[f.ID] = f.Data
+		}
+	}
+	return m
+}
+
+// FramesByID returns the data of every frame with the given ID in the order
+// they were decoded.
+func (l *List) FramesByID(id string) [][]byte {
+	var frames [][]byte
+	for _, f := range l.frames {
+		if f.ID == id {
+			frames = append(frames, f.Data)
+		}
+	}
+	return frames
+}
+
+// FrameOrder returns the ID of every frame in the order they were decoded,
+// including repeated IDs.
+func (l *List) FrameOrder() []string {
+	order := make([]string, len(l.frames))
+	for i, f := range l.frames {
+		order[i] = f.ID
+	}
+	return order
+}
+
+// EachFrame calls fn for each frame in the order they were decoded, stopping
+// at the first error returned by fn.
+func (l *List) EachFrame(fn func(id string, data []byte) error) error {
+	for _, f := range l.frames {
+		if err := fn(f.ID, f.Data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetFrame returns the data of the first frame with the given ID, or if there
+// isn't one the first frame whose ID is id in canonical upper case, such as a
+// TIT2 frame written as "tit2" by a buggy encoder. The IDs of the frames are
+// left as decoded.
+func (l *List) GetFrame(id string) ([]byte, bool) {
+	if f := l.frame(id); f != nil {
+		return f.Data, true
+	}
+
+	if l.index == nil {
+		l.index = make(map[string]int)
+		for i, f := range l.frames {
+			canonical := strings.ToUpper(f.ID)
+			if _, ok := l.index[canonical]; !ok {
+				l.index[canonical] = i
+			}
+		}
+	}
+
+	if i, ok := l.index[id]; ok {
+		return l.frames[i].Data, true
+	}
+	return nil, false
+}
+
+// GetFrameFold returns the data of the first frame whose ID matches id
+// regardless of case.
+func (l *List) GetFrameFold(id string) ([]byte, bool) {
+	if f := l.frame(id); f != nil {
+		return f.Data, true
+	}
+
+	for _, f := range l.frames {
+		if strings.EqualFold(f.ID, id) {
+			return f.Data, true
+		}
+	}
+	return nil, false
+}
+
+// FrameFlags returns the flags of the first frame with the given ID as they
+// were decoded.
+func (l *List) FrameFlags(id string) uint16 {
+	if f := l.frame(id); f != nil {
+		return f.Flags
+	}
+	return 0
+}
+
+// SetFrameFlags sets the flags written on encode for every frame with the
+// given ID.
+func (l *List) SetFrameFlags(id string, flags uint16) {
+	for i := range l.frames {
+		if l.frames[i].ID == id {
+			l.frames[i].Flags = flags
+		}
+	}
+}
+
+// FrameAt returns the i'th frame in the frame order.
+func (l *List) FrameAt(i int) id3v2.Frame {
+	return l.frames[i]
+}
+
+// SetFrameAt replaces the ID and data of the i'th frame in the frame order,
+// keeping its place and raw marker. A frame whose data changes keeps only
+// its status flags as with SetFrame, otherwise it's only renamed.
+func (l *List) SetFrameAt(i int, id string, data []byte) {
+	l.frames[i].ID = id
+	l.setData(&l.frames[i], data)
+	l.change()
+}
+
+// RemoveFrameAt removes the i'th frame in the frame order.
+func (l *List) RemoveFrameAt(i int) {
+	l.frames = append(l.frames[:i], l.frames[i+1:]...)
+	l.change()
+}
+
+// AppendFrame adds f to the end of the frame order with its flags, extra
+// header and raw marker, such as a frame returned by FrameAt of another tag
+// of the same version. A frame whose extra header doesn't match its flags
+// keeps only its status flags.
+func (l *List) AppendFrame(f id3v2.Frame) {
+	switch {
+	case l.format.ExtraHeaderSize == nil:
+		f.Flags, f.ExtraHeader = 0, nil
+	case len(f.ExtraHeader) != l.format.ExtraHeaderSize(f.Flags):
+		f.Flags, f.ExtraHeader = f.Flags&l.format.StatusFlags, nil
+	}
+
+	l.frames = append(l.frames, f)
+	l.change()
+}
+
+// SetFrame sets the data of a single frame, adding it to the end of the frame
+// order if it doesn't already exist. Any other frames with the same ID are
+// removed. An existing frame keeps only its status flags unless its data is
+// unchanged, since flags like compression don't hold for the new data. A
+// frame passed through unsupported stays so while its data is unchanged.
+func (l *List) SetFrame(id string, data []byte) {
+	f := l.frame(id)
+	if f == nil {
+		l.frames = append(l.frames, id3v2.Frame{ID: id, Data: data})
+	} else {
+		if l.setData(f, data) {
+			f.Raw = false
+		}
+		l.removeDuplicates(id)
+	}
+
+	l.change()
+}
+
+// AddFrame adds a frame to the end of the frame order, keeping any frames
+// that already have the same ID.
+func (l *List) AddFrame(id string, data []byte) {
+	l.frames = append(l.frames, id3v2.Frame{ID: id, Data: data})
+	l.change()
+}
+
+// SetFrames replaces the frames of the tag. Frames that still exist keep
+// their place in the frame order, new frames are added to the end. As with
+// SetFrame, frames whose data changes keep only their status flags, and
+// unsupported frames whose data is unchanged are still passed through.
+func (l *List) SetFrames(m map[string][]byte) {
+	var frames []id3v2.Frame
+	seen := make(map[string]bool)
+
+	for _, f := range l.frames {
+		data, ok := m[f.ID]
+		if !ok || seen[f.ID] {
+			continue
+		}
+		seen[f.ID] = true
+
+		if l.setData(&f, data) {
+			f.Raw = false
+		}
+		frames = append(frames, f)
+	}
+
+	var ids []string
+	for id := range m {
+		if !seen[id] {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		frames = append(frames, id3v2.Frame{ID: id, Data: m[id]})
+	}
+
+	l.frames = frames
+	l.change()
+}
+
+// CompactFrameOrder removes frames that repeat the frame right before them
+// with the same ID and data, as left by adding the same frame twice. Frames
+// sharing an ID but holding different data, like several COMM frames, are
+// kept. The frame order is the list of frames itself, so it never holds
+// dangling IDs to remove. The tag is left as decoded if nothing is removed.
+func (l *List) CompactFrameOrder() {
+	frames := l.frames[:0]
+	for _, f := range l.frames {
+		if n := len(frames); n > 0 && frames[n-1].ID == f.ID && bytes.Equal(frames[n-1].Data, f.Data) {
+			continue
+		}
+		frames = append(frames, f)
+	}
+
+	if len(frames) < len(l.frames) {
+		l.frames = frames
+		l.change()
+	}
+}
+
+// Padding returns the number of bytes of padding that followed the frames
+// when the tag was decoded. Changing the frames resets it to 0.
+func (l *List) Padding() uint32 {
+	return l.padding
+}
+
+// Entries returns every frame in order. The frames aren't copied.
+func (l *List) Entries() []id3v2.Frame {
+	return l.frames
+}
+
+// Changed returns true if the frames have changed since the tag was decoded.
+func (l *List) Changed() bool {
+	return l.changed
+}
+
+// FramesSize returns the number of bytes the frames take up when encoded,
+// including their headers.
+func (l *List) FramesSize() uint32 {
+	size := uint32(0)
+	for _, f := range l.frames {
+		size += uint32(l.format.HeaderSize + len(f.ExtraHeader) + len(f.Data))
+	}
+	return size
+}
+
+// Of returns every frame of a tag in order, for encoding tags of another
+// version or type.
+func Of(tag id3v2.Tag) []id3v2.Frame {
+	var frames []id3v2.Frame
+	tag.EachFrame(func(id string, data []byte) error {
+		frames = append(frames, id3v2.Frame{ID: id, Flags: tag.FrameFlags(id), Data: data})
+		return nil
+	})
+	return frames
+}
+
+// frame returns the first frame with the given ID, or nil if there isn't one.
+func (l *List) frame(id string) *id3v2.Frame {
+	for i := range l.frames {
+		if l.frames[i].ID == id {
+			return &l.frames[i]
+		}
+	}
+	return nil
+}
+
+// setData replaces the data of f, dropping the flags and extra header that
+// describe how the old data was stored unless it's unchanged. It returns true
+// if the data changed.
+func (l *List) setData(f *id3v2.Frame, data []byte) bool {
+	if bytes.Equal(f.Data, data) {
+		return false
+	}
+
+	f.Data = data
+	f.Flags &= l.format.StatusFlags
+	f.ExtraHeader = nil
+	return true
+}
+
+// removeDuplicates removes all but the first frame with the given ID.
+func (l *List) removeDuplicates(id string) {
+	frames := l.frames[:0]
+	seen := false
+	for _, f := range l.frames {
+		if f.ID == id {
+			if seen {
+				continue
+			}
+			seen = true
+		}
+		frames = append(frames, f)
+	}
+	l.frames = frames
+}
+
+// change resets what no longer holds once the frames change.
+func (l *List) change() {
+	l.index = nil
+	l.padding = 0
+	l.changed = true
+}
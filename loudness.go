@@ -0,0 +1,83 @@
+package id3v2
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// LoudnessInfo returns the track and album gain in dB from either the
+// ReplayGain TXXX frames or, failing that, the iTunes Sound Check "iTunNORM"
+// comment. Sound Check only records a track gain so albumGain is zero when it
+// is used. ok is false if neither convention is present.
+func LoudnessInfo(tag Tag) (trackGain, albumGain float64, ok bool) {
+	var hasTrack, hasAlbum bool
+
+	for _, data := range tag.FramesByID("TXXX") {
+		desc, value, err := ParseTXXX(data)
+		if err != nil {
+			continue
+		}
+
+		switch strings.ToUpper(desc) {
+		case "REPLAYGAIN_TRACK_GAIN":
+			trackGain, hasTrack = parseGain(value)
+		case "REPLAYGAIN_ALBUM_GAIN":
+			albumGain, hasAlbum = parseGain(value)
+		}
+	}
+	if hasTrack || hasAlbum {
+		return trackGain, albumGain, true
+	}
+
+	for _, data := range tag.FramesByID("COMM") {
+		_, desc, text, err := parseCOMM(data)
+		if err != nil || desc != "iTunNORM" {
+			continue
+		}
+
+		if gain, ok := parseSoundCheck(text); ok {
+			return gain, 0, true
+		}
+	}
+
+	return 0, 0, false
+}
+
+// parseGain parses a ReplayGain value such as "-6.48 dB".
+func parseGain(s string) (float64, bool) {
+	s = strings.TrimSpace(s)
+	s = strings.TrimSpace(strings.TrimSuffix(strings.TrimSuffix(s, "dB"), "db"))
+
+	gain, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, false
+	}
+	return gain, true
+}
+
+// parseSoundCheck converts an iTunNORM value, ten space separated hex words,
+// to a gain in dB. The first two words are the left and right channel volume
+// adjustments in milliwatts relative to 1/1000 W.
+func parseSoundCheck(s string) (float64, bool) {
+	fields := strings.Fields(s)
+	if len(fields) < 2 {
+		return 0, false
+	}
+
+	var max uint64
+	for _, f := range fields[:2] {
+		v, err := strconv.ParseUint(f, 16, 32)
+		if err != nil {
+			return 0, false
+		}
+		if v > max {
+			max = v
+		}
+	}
+	if max == 0 {
+		return 0, false
+	}
+
+	return -10 * math.Log10(float64(max)/1000), true
+}
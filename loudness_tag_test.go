@@ -0,0 +1,60 @@
+package id3v2_test
+
+import (
+	"testing"
+
+	"github.com/jlubawy/go-id3v2"
+)
+
+func TestLoudnessInfo(t *testing.T) {
+	var (
+		rva2     = []byte("track\x00\x01\xFC\xC3\x00")
+		rgad     = []byte{0x3F, 0x80, 0x00, 0x00, 0x2C, 0x2B, 0x4C, 0x32}
+		track    = []byte("\x00REPLAYGAIN_TRACK_GAIN\x00-6.48 dB")
+		album    = []byte("\x00REPLAYGAIN_ALBUM_GAIN\x00-7.50 dB")
+		peak     = []byte("\x00REPLAYGAIN_TRACK_PEAK\x000.988")
+		lower    = []byte("\x00replaygain_track_gain\x00+1.25 dB")
+		itunNORM = []byte("\x00engiTunNORM\x00 000003E8 00002710 00000000 00000000 00000000 00000000 00000000 00000000 00000000 00000000")
+	)
+
+	tests := []struct {
+		name   string
+		frames []interface{}
+		track  float64
+		album  float64
+		ok     bool
+	}{
+		{
+			name:   "ReplayGain over Sound Check",
+			frames: []interface{}{"RVA2", rva2, "RGAD", rgad, "TXXX", peak, "TXXX", track, "COMM", itunNORM, "TXXX", album},
+			track:  -6.48,
+			album:  -7.5,
+			ok:     true,
+		},
+		{
+			name:   "lower case ReplayGain",
+			frames: []interface{}{"TXXX", lower},
+			track:  1.25,
+			ok:     true,
+		},
+		{
+			name:   "Sound Check",
+			frames: []interface{}{"RVA2", rva2, "RGAD", rgad, "TXXX", peak, "COMM", itunNORM},
+			track:  -10,
+			ok:     true,
+		},
+		{
+			name:   "neither",
+			frames: []interface{}{"RVA2", rva2, "RGAD", rgad, "TXXX", peak},
+		},
+	}
+
+	for _, test := range tests {
+		tag := buildTag(t, test.frames...)
+
+		track, album, ok := id3v2.LoudnessInfo(tag)
+		if ok != test.ok || track != test.track || album != test.album {
+			t.Errorf("%s: expected %v, %v, %v but got %v, %v, %v", test.name, test.track, test.album, test.ok, track, album, ok)
+		}
+	}
+}
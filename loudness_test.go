@@ -0,0 +1,30 @@
+package id3v2
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseSoundCheck(t *testing.T) {
+	// 0x000003E8 == 1000 is unity gain, 0x00002710 == 10000 is -10 dB
+	gain, ok := parseSoundCheck(" 000003E8 00002710 00000000 00000000 00000000 00000000 00000000 00000000 00000000 00000000")
+	if !ok {
+		t.Fatal("expected iTunNORM value to parse")
+	}
+	if math.Abs(gain-(-10)) > 1e-9 {
+		t.Errorf("expected gain of -10 dB but got %f", gain)
+	}
+}
+
+func TestParseTXXXGain(t *testing.T) {
+	desc, value, err := ParseTXXX([]byte("\x00REPLAYGAIN_TRACK_GAIN\x00-6.48 dB"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if desc != "REPLAYGAIN_TRACK_GAIN" {
+		t.Errorf("expected description REPLAYGAIN_TRACK_GAIN but got %s", desc)
+	}
+	if gain, ok := parseGain(value); !ok || gain != -6.48 {
+		t.Errorf("expected gain of -6.48 dB but got %f", gain)
+	}
+}
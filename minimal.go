@@ -0,0 +1,25 @@
+package id3v2
+
+// MinimalTag returns a tag of the latest registered version holding only a
+// title and artist, for stamping generated audio with basic metadata. It
+// returns nil if no version is registered.
+func MinimalTag(title, artist string) Tag {
+	v, err := latestVersion()
+	if err != nil {
+		return nil
+	}
+	ver, err := lookupEncoder(v)
+	if err != nil {
+		return nil
+	}
+
+	titleID, artistID := "TIT2", "TPE1"
+	if ver.major < 3 {
+		titleID, artistID = "TT2", "TP1"
+	}
+
+	tag := ver.newTag()
+	tag.SetFrame(titleID, EncodeTextFrame(title, EncodingAuto))
+	tag.SetFrame(artistID, EncodeTextFrame(artist, EncodingAuto))
+	return tag
+}
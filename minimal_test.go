@@ -0,0 +1,36 @@
+package id3v2_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/jlubawy/go-id3v2"
+	"github.com/jlubawy/go-id3v2/id3v230"
+)
+
+func TestMinimalTag(t *testing.T) {
+	tag := id3v2.MinimalTag("Test", "Ålbert ☃")
+	if tag == nil {
+		t.Fatal("expected a tag but got nil")
+	}
+
+	buf := &bytes.Buffer{}
+	if err := id3v230.Encode(buf, tag); err != nil {
+		t.Fatal(err)
+	}
+
+	tag, _, err := id3v2.Decode(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if order := tag.FrameOrder(); len(order) != 2 || order[0] != "TIT2" || order[1] != "TPE1" {
+		t.Errorf("expected frames [TIT2 TPE1] but got %v", order)
+	}
+	if s := string(tag.Frames()["TIT2"]); s != "\x00Test\x00" {
+		t.Errorf("expected TIT2 '\\x00Test\\x00' but got %q", s)
+	}
+	if s, err := id3v2.DecodeTextWithOptions(tag.Frames()["TPE1"], id3v2.TextOptions{}); err != nil || s != "Ålbert ☃" {
+		t.Errorf("expected TPE1 'Ålbert ☃' but got %q (%v)", s, err)
+	}
+}
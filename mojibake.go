@@ -0,0 +1,61 @@
+package id3v2
+
+import (
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// FixMojibake repairs text frames declared as ISO-8859-1 which actually
+// contain UTF-8, so that "é" reads as "é" rather than "Ã©". The repaired frames
+// are re-encoded as UTF-16 which every ID3v2 version supports. Each frame is
+// repaired in place, so repeated frames like TXXX are all kept. It returns the
+// number of frames fixed.
+func FixMojibake(tag Tag) int {
+	n := 0
+	for i, id := range tag.FrameOrder() {
+		if !strings.HasPrefix(id, "T") {
+			continue
+		}
+
+		data := tag.FrameAt(i).Data
+		if len(data) < 1 || data[0] != encodingISO88591 {
+			continue
+		}
+
+		if !isMojibake(data[1:]) {
+			continue
+		}
+
+		fixed := append([]byte{encodingUTF16}, encodeUTF16(string(data[1:]))...)
+		if id == "TXXX" {
+			// The description and value each need their own BOM
+			desc, value, _ := splitString(encodingISO88591, data[1:])
+			fixed, _ = EncodeTXXX(string(desc), trimNull(string(value)), EncodingUTF16)
+		}
+
+		tag.SetFrameAt(i, id, fixed)
+		n++
+	}
+	return n
+}
+
+// isMojibake returns true if b contains non-ASCII characters that form valid
+// UTF-8, which is very unlikely to happen by chance with real ISO-8859-1 text.
+func isMojibake(b []byte) bool {
+	for _, c := range b {
+		if c >= 0x80 {
+			return utf8.Valid(b)
+		}
+	}
+	return false
+}
+
+// encodeUTF16 encodes s as little-endian UTF-16 with a BOM.
+func encodeUTF16(s string) []byte {
+	b := []byte{0xFF, 0xFE}
+	for _, u := range utf16.Encode([]rune(s)) {
+		b = append(b, byte(u), byte(u>>8))
+	}
+	return b
+}
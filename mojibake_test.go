@@ -0,0 +1,71 @@
+package id3v2_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/jlubawy/go-id3v2"
+)
+
+func TestFixMojibake(t *testing.T) {
+	tag, err := id3v2.NewTag("id3v2.3.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tag.SetFrame("TIT2", []byte("\x00Caf\xC3\xA9"))
+	tag.SetFrame("TPE1", []byte("\x00Caf\xE9"))
+
+	if n := id3v2.FixMojibake(tag); n != 1 {
+		t.Errorf("expected 1 frame to be fixed but got %d", n)
+	}
+
+	expected := []byte{0x01, 0xFF, 0xFE, 'C', 0, 'a', 0, 'f', 0, 0xE9, 0}
+	if data := tag.Frames()["TIT2"]; !bytes.Equal(data, expected) {
+		t.Errorf("expected TIT2 frame %v but got %v", expected, data)
+	}
+	if data := tag.Frames()["TPE1"]; !bytes.Equal(data, []byte("\x00Caf\xE9")) {
+		t.Errorf("expected real ISO-8859-1 TPE1 frame to be untouched but got %v", data)
+	}
+}
+
+func TestFixMojibakeRepeatedFrames(t *testing.T) {
+	tag, err := id3v2.NewTag("id3v2.3.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tag.AddFrame("TXXX", []byte("\x00one\x00Caf\xC3\xA9"))
+	tag.AddFrame("COMM", []byte("\x00engone\x00Caf\xC3\xA9"))
+	tag.AddFrame("TXXX", []byte("\x00two\x00plain"))
+	tag.AddFrame("COMM", []byte("\x00engtwo\x00text"))
+	tag.AddFrame("TXXX", []byte("\x00three\x00na\xC3\xAFve"))
+
+	if n := id3v2.FixMojibake(tag); n != 2 {
+		t.Errorf("expected 2 frames to be fixed but got %d", n)
+	}
+
+	expected := []string{"TXXX", "COMM", "TXXX", "COMM", "TXXX"}
+	order := tag.FrameOrder()
+	if len(order) != len(expected) {
+		t.Fatalf("expected frames %v but got %v", expected, order)
+	}
+	for i := range expected {
+		if order[i] != expected[i] {
+			t.Fatalf("expected frames %v but got %v", expected, order)
+		}
+	}
+
+	values := []string{"Café", "plain", "naïve"}
+	for i, data := range tag.FramesByID("TXXX") {
+		if _, value, err := id3v2.ParseTXXX(data); err != nil || value != values[i] {
+			t.Errorf("expected TXXX value '%s' but got '%s' (%v)", values[i], value, err)
+		}
+	}
+
+	// Comments aren't text information frames and are left alone
+	comments := tag.FramesByID("COMM")
+	if !bytes.Equal(comments[0], []byte("\x00engone\x00Caf\xC3\xA9")) || !bytes.Equal(comments[1], []byte("\x00engtwo\x00text")) {
+		t.Errorf("expected COMM frames to be untouched but got %q", comments)
+	}
+}
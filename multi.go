@@ -0,0 +1,137 @@
+package id3v2
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"reflect"
+)
+
+// An updater is a tag that can say whether it updates an earlier tag, as
+// marked in the extended header of ID3v2.4 tags.
+type updater interface {
+	IsUpdate() bool
+}
+
+// DecodeAll decodes a tag and the update tags that immediately follow it, as
+// written by ID3v2.4 encoders when content doesn't fit in a single tag. The
+// frames of each update are added to the first tag, replacing the frames of
+// IDs that may only appear once, like text frames other than TXXX. Decoding
+// stops at the first following tag that isn't an update, whose frames are
+// left out.
+func DecodeAll(r io.Reader) (Tag, string, error) {
+	var tag Tag
+	var ver string
+
+	br := bufio.NewReader(r)
+	for {
+		h, err := br.Peek(headerSize)
+		if err != nil || !bytes.Equal(h[0:3], FileIdentifier) {
+			break
+		}
+
+		// Limit each decode to its own tag so that padding is skipped and
+		// the next tag can be found
+		lr := io.LimitReader(br, int64(tagSize(h)))
+
+		next, v, err := Decode(lr)
+		if err != nil {
+			return nil, v, err
+		}
+		if _, err := io.Copy(ioutil.Discard, lr); err != nil {
+			return nil, v, err
+		}
+
+		if tag == nil {
+			tag, ver = next, v
+			continue
+		}
+
+		if u, ok := next.(updater); !ok || !u.IsUpdate() {
+			break
+		}
+		mergeUpdate(tag, next)
+	}
+
+	if tag == nil {
+		return nil, "", ErrFormat
+	}
+
+	return tag, ver, nil
+}
+
+// mergeUpdate adds the frames of the update tag to tag, first removing the
+// frames it replaces.
+func mergeUpdate(tag, update Tag) {
+	sameVersion := reflect.TypeOf(tag) == reflect.TypeOf(update)
+
+	for i, id := range update.FrameOrder() {
+		if isSingletonFrame(id) {
+			for j := len(tag.FrameOrder()) - 1; j >= 0; j-- {
+				if tag.FrameAt(j).ID == id {
+					tag.RemoveFrameAt(j)
+				}
+			}
+		}
+
+		// Flags only carry over between tags of the same version
+		f := update.FrameAt(i)
+		if sameVersion {
+			tag.AppendFrame(f)
+		} else {
+			tag.AddFrame(f.ID, f.Data)
+		}
+	}
+}
+
+// isHeader returns true if h looks like a tag header: the file identifier
+// followed by a version, flags with the undefined bits cleared and a
+// synchsafe size.
+func isHeader(h []byte) bool {
+	if !bytes.Equal(h[0:3], FileIdentifier) || h[3] == 0xFF || h[4] == 0xFF || h[5]&0x0F != 0 {
+		return false
+	}
+	for _, b := range h[6:10] {
+		if b >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
+
+// Scan returns an iterator over every tag found in r, such as a stream of
+// concatenated MP3 files with a tag before each part's audio. Each call
+// returns the next tag and its offset in the stream, or io.EOF once the
+// stream is exhausted. A tag which fails to decode returns its error and
+// scanning resumes after it on the next call.
+func Scan(r io.Reader) func() (Tag, int64, error) {
+	br := bufio.NewReader(r)
+	offset := int64(0)
+
+	return func() (Tag, int64, error) {
+		for {
+			h, err := br.Peek(headerSize)
+			if err != nil {
+				return nil, offset, err
+			}
+
+			if !isHeader(h) {
+				br.Discard(1)
+				offset++
+				continue
+			}
+
+			start := offset
+			lr := &io.LimitedReader{R: br, N: int64(tagSize(h))}
+
+			tag, _, err := Decode(lr)
+			if _, cerr := io.Copy(ioutil.Discard, lr); cerr != nil && err == nil {
+				err = cerr
+			}
+			offset += int64(tagSize(h)) - lr.N
+
+			return tag, start, err
+		}
+	}
+}
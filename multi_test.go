@@ -0,0 +1,88 @@
+package id3v2_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/jlubawy/go-id3v2"
+	"github.com/jlubawy/go-id3v2/id3v240"
+)
+
+func TestDecodeAll(t *testing.T) {
+	base := id3v240.NewTag()
+	base.AddFrame("TIT2", []byte("\x03Old"))
+	base.AddFrame("TPE1", []byte("\x03Band"))
+	base.AddFrame("APIC", []byte("\x03a"))
+	base.AddFrame("APIC", []byte("\x03b"))
+
+	buf := &bytes.Buffer{}
+	if err := id3v240.Encode(buf, base); err != nil {
+		t.Fatal(err)
+	}
+
+	update := []byte{
+		'I', 'D', '3', 4, 0, id3v240.HeaderFlagExtendedHeader, 0, 0, 0, 46,
+		0, 0, 0, 7, 1, id3v240.ExtendedHeaderFlagUpdate, 0,
+		'T', 'I', 'T', '2', 0, 0, 0, 5, 0, 0,
+		3, 'N', 'e', 'w', '!',
+		'A', 'P', 'I', 'C', 0, 0, 0, 2, 0, 0,
+		3, 'c',
+		'A', 'P', 'I', 'C', 0, 0, 0, 2, 0, 0,
+		3, 'd',
+	}
+	buf.Write(update)
+
+	// Without the update flag a following tag isn't merged
+	other := []byte{
+		'I', 'D', '3', 4, 0, 0, 0, 0, 0, 15,
+		'T', 'A', 'L', 'B', 0, 0, 0, 5, 0, 0,
+		3, 'L', 'o', 's', 't',
+	}
+	buf.Write(other)
+
+	tag, _, err := id3v2.DecodeAll(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data := tag.FramesByID("TIT2"); len(data) != 1 || !bytes.Equal(data[0], []byte("\x03New!")) {
+		t.Errorf("expected TIT2 frame to be replaced by %q but got %q", "\x03New!", data)
+	}
+	if data := tag.Frames()["TPE1"]; !bytes.Equal(data, []byte("\x03Band")) {
+		t.Errorf("expected TPE1 frame %q but got %q", "\x03Band", data)
+	}
+	if n := len(tag.FramesByID("APIC")); n != 4 {
+		t.Errorf("expected the APIC frames of both tags but got %d", n)
+	}
+	if _, ok := tag.Frames()["TALB"]; ok {
+		t.Error("expected the frames of a tag which isn't an update to be left out")
+	}
+}
+
+func TestScan(t *testing.T) {
+	audio := []byte{0xFF, 0xFB, 0x90, 0x00, 'I', 'D', '3'}
+
+	b := append(append([]byte{}, testTag...), audio...)
+	b = append(b, testTag...)
+	b = append(b, audio...)
+
+	next := id3v2.Scan(bytes.NewReader(b))
+
+	for _, expected := range []int64{0, int64(len(testTag) + len(audio))} {
+		tag, offset, err := next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if offset != expected {
+			t.Errorf("expected tag at offset %d but got %d", expected, offset)
+		}
+		if _, ok := tag.Frames()["TIT2"]; !ok {
+			t.Errorf("expected tag at offset %d to have a TIT2 frame", offset)
+		}
+	}
+
+	if _, _, err := next(); err != io.EOF {
+		t.Errorf("expected io.EOF after the last tag but got %v", err)
+	}
+}
@@ -0,0 +1,114 @@
+package id3v2
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// NormalizeOptions choose the cleanups Normalize applies. The zero value
+// leaves the tag unchanged.
+type NormalizeOptions struct {
+	// UppercaseIDs uppercases frame IDs, e.g. "tit2" becomes "TIT2".
+	UppercaseIDs bool
+
+	// FixMojibake repairs text frames declared as ISO-8859-1 which actually
+	// contain UTF-8, as FixMojibake does.
+	FixMojibake bool
+
+	// TrimText removes trailing whitespace and nulls from text frames.
+	TrimText bool
+
+	// ConvertEncoding re-encodes every text frame in Encoding. EncodingAuto
+	// picks ISO-8859-1 or UTF-16 for each frame. UTF-8 and UTF-16BE are only
+	// valid in ID3v2.4.0 tags.
+	ConvertEncoding bool
+	Encoding        Encoding
+
+	// DropEmpty removes text frames with no text and other frames with no
+	// data.
+	DropEmpty bool
+}
+
+// Normalize applies the cleanups chosen by opts to every frame of the tag in
+// one pass. Frames are edited in place, keeping their order, and frames whose
+// data is unchanged keep their flags and extra header, while those re-encoded
+// keep only their status flags. Text frames that fail to decode are left as
+// they are. It returns an error if the encoding to convert to is invalid.
+func Normalize(tag Tag, opts NormalizeOptions) error {
+	if opts.ConvertEncoding && opts.Encoding != EncodingAuto && opts.Encoding > EncodingUTF8 {
+		return fmt.Errorf("id3v2: can't normalize to unknown text encoding $%02X", byte(opts.Encoding))
+	}
+
+	for i, n := 0, len(tag.FrameOrder()); i < n; {
+		f := tag.FrameAt(i)
+
+		id := f.ID
+		if opts.UppercaseIDs {
+			id = strings.ToUpper(id)
+		}
+
+		data, keep := f.Data, true
+		if isPlainTextFrame(id) {
+			data, keep = normalizeText(f.Data, opts)
+		} else if opts.DropEmpty && len(f.Data) == 0 {
+			keep = false
+		}
+
+		switch {
+		case !keep:
+			tag.RemoveFrameAt(i)
+			n--
+			continue
+		case id != f.ID || !bytes.Equal(data, f.Data):
+			tag.SetFrameAt(i, id, data)
+		}
+		i++
+	}
+
+	return nil
+}
+
+// isPlainTextFrame returns true for text information frames other than the
+// user defined TXXX, including the three character IDs of ID3v2.2.0.
+func isPlainTextFrame(id string) bool {
+	return strings.HasPrefix(id, "T") && id != "TXXX" && id != "TXX"
+}
+
+// normalizeText applies the cleanups of opts to the data of a text frame,
+// returning the data unchanged if there's nothing to do and false if the
+// frame should be dropped.
+func normalizeText(data []byte, opts NormalizeOptions) ([]byte, bool) {
+	if len(data) < 1 {
+		return data, !opts.DropEmpty
+	}
+
+	enc := Encoding(data[0])
+
+	var s string
+	if opts.FixMojibake && enc == EncodingISO88591 && isMojibake(data[1:]) {
+		s = trimNull(string(data[1:]))
+		enc = EncodingUTF16
+	} else {
+		var err error
+		if s, err = DecodeTextFrame(data); err != nil {
+			return data, true
+		}
+	}
+	orig, origEnc := s, Encoding(data[0])
+
+	if opts.TrimText {
+		s = strings.TrimRight(s, " \t\r\n\x00")
+	}
+	if opts.DropEmpty && s == "" {
+		return nil, false
+	}
+	if opts.ConvertEncoding {
+		enc = resolveEncoding(opts.Encoding, s)
+	}
+
+	if s == orig && enc == origEnc {
+		return data, true
+	}
+	return EncodeTextFrame(s, enc), true
+}
@@ -0,0 +1,106 @@
+package id3v2_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/jlubawy/go-id3v2"
+)
+
+func TestNormalize(t *testing.T) {
+	tag, err := id3v2.NewTag("id3v2.3.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tag.AddFrame("tit2", []byte("\x00Caf\xC3\xA9"))
+	tag.AddFrame("TPE1", []byte("\x00Artist  \x00\x00"))
+	tag.AddFrame("TALB", []byte("\x00 \x00"))
+	tag.AddFrame("PRIV", []byte{})
+	tag.AddFrame("TCON", []byte("\x00Rock"))
+	tag.SetFrameFlags("TCON", 0x2000)
+
+	opts := id3v2.NormalizeOptions{
+		UppercaseIDs: true,
+		FixMojibake:  true,
+		TrimText:     true,
+		DropEmpty:    true,
+	}
+	if err := id3v2.Normalize(tag, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"TIT2", "TPE1", "TCON"}
+	order := tag.FrameOrder()
+	if len(order) != len(expected) {
+		t.Fatalf("expected frames %v but got %v", expected, order)
+	}
+	for i := range expected {
+		if order[i] != expected[i] {
+			t.Fatalf("expected frames %v but got %v", expected, order)
+		}
+	}
+
+	texts := map[string]string{"TIT2": "Café", "TPE1": "Artist", "TCON": "Rock"}
+	for id, s := range texts {
+		if got, err := id3v2.DecodeTextFrame(tag.Frames()[id]); err != nil || got != s {
+			t.Errorf("expected %s '%s' but got '%s' (%v)", id, s, got, err)
+		}
+	}
+	if data := tag.Frames()["TCON"]; !bytes.Equal(data, []byte("\x00Rock")) {
+		t.Errorf("expected the clean TCON to be untouched but got %q", data)
+	}
+	if flags := tag.FrameFlags("TCON"); flags != 0x2000 {
+		t.Errorf("expected the TCON flags to be kept but got %04X", flags)
+	}
+
+	if err := id3v2.Normalize(tag, id3v2.NormalizeOptions{ConvertEncoding: true, Encoding: id3v2.EncodingUTF16}); err != nil {
+		t.Fatal(err)
+	}
+	for id := range texts {
+		if data := tag.Frames()[id]; data[0] != byte(id3v2.EncodingUTF16) {
+			t.Errorf("expected %s to be re-encoded as UTF-16 but got encoding $%02X", id, data[0])
+		}
+	}
+
+	if err := id3v2.Normalize(tag, id3v2.NormalizeOptions{ConvertEncoding: true, Encoding: 0x04}); err == nil {
+		t.Error("expected an error for an invalid encoding")
+	}
+}
+
+func TestNormalizeKeepsFrameState(t *testing.T) {
+	b := []byte{
+		'I', 'D', '3', 3, 0, 0, 0, 0, 0, 51,
+		'Z', 'Z', 'Z', 'Z', 0, 0, 0, 4, 0, 0,
+		'a', 'b', 'c', 'd',
+		'T', 'I', 'T', '2', 0, 0, 0, 9, 0, 0x80,
+		0, 0, 0, 5, 0x78, 0x9C, 0x01, 0x02, 0x03,
+		't', 'p', 'e', '1', 0, 0, 0, 8, 0, 0,
+		0, 'A', 'r', 't', 'i', 's', 't', ' ',
+	}
+
+	tag, _, err := id3v2.Decode(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := id3v2.Normalize(tag, id3v2.NormalizeOptions{UppercaseIDs: true, TrimText: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The unsupported ZZZZ frame is still passed through and the compressed
+	// TIT2 keeps its decompressed size
+	expected := append([]byte{'I', 'D', '3', 3, 0, 0, 0, 0, 0, 51}, b[10:43]...)
+	expected = append(expected,
+		'T', 'P', 'E', '1', 0, 0, 0, 8, 0, 0,
+		0, 'A', 'r', 't', 'i', 's', 't', 0,
+	)
+
+	buf := &bytes.Buffer{}
+	if err := id3v2.EncodeAs(buf, tag, "id3v2.3.0"); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), expected) {
+		t.Errorf("expected %v but got %v", expected, buf.Bytes())
+	}
+}
@@ -0,0 +1,55 @@
+package id3v2
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// RGAD is the non-standard replay gain adjustment frame written by some older
+// tools instead of ReplayGain TXXX frames.
+//
+// Peak amplitude                       $xx xx xx xx
+// Radio replay gain adjustment         $xx xx
+// Audiophile replay gain adjustment    $xx xx
+type RGAD struct {
+	Peak       float32
+	Radio      GainAdjustment
+	Audiophile GainAdjustment
+}
+
+// A GainAdjustment is one of the two replay gain adjustments of an RGAD frame.
+//
+// Name code         %xxx
+// Originator code   %xxx
+// Sign bit          %x
+// Adjustment        %xxxxxxxxx (in 0.1 dB)
+type GainAdjustment struct {
+	Name       byte // 0 not set, 1 radio, 2 audiophile
+	Originator byte // 0 unspecified, 1 artist, 2 user, 3 automatic
+	Adjustment float64
+}
+
+// ParseRGAD parses the data of an RGAD frame.
+func ParseRGAD(data []byte) (RGAD, error) {
+	if len(data) < 8 {
+		return RGAD{}, errShortFrame("RGAD")
+	}
+
+	return RGAD{
+		Peak:       math.Float32frombits(binary.BigEndian.Uint32(data[0:4])),
+		Radio:      parseGainAdjustment(binary.BigEndian.Uint16(data[4:6])),
+		Audiophile: parseGainAdjustment(binary.BigEndian.Uint16(data[6:8])),
+	}, nil
+}
+
+func parseGainAdjustment(v uint16) GainAdjustment {
+	g := GainAdjustment{
+		Name:       byte(v>>13) & 0x7,
+		Originator: byte(v>>10) & 0x7,
+		Adjustment: float64(v&0x1FF) / 10,
+	}
+	if v&(1<<9) != 0 {
+		g.Adjustment = -g.Adjustment
+	}
+	return g
+}
@@ -0,0 +1,27 @@
+package id3v2
+
+import (
+	"testing"
+)
+
+func TestParseRGAD(t *testing.T) {
+	// Peak 1.0, radio -6.5 dB set automatically, audiophile +2.0 dB set by user
+	rgad, err := ParseRGAD([]byte{0x3F, 0x80, 0x00, 0x00, 0x2E, 0x41, 0x48, 0x14})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if rgad.Peak != 1.0 {
+		t.Errorf("expected peak 1.0 but got %f", rgad.Peak)
+	}
+	if g := rgad.Radio; g.Name != 1 || g.Originator != 3 || g.Adjustment != -6.5 {
+		t.Errorf("expected radio adjustment {1 3 -6.5} but got %v", g)
+	}
+	if g := rgad.Audiophile; g.Name != 2 || g.Originator != 2 || g.Adjustment != 2.0 {
+		t.Errorf("expected audiophile adjustment {2 2 2} but got %v", g)
+	}
+
+	if _, err := ParseRGAD([]byte{0x3F}); err == nil {
+		t.Error("expected an error for a truncated RGAD frame")
+	}
+}
@@ -0,0 +1,77 @@
+package id3v2
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// A lossReporter is a tag that can describe what of it encoding wouldn't
+// preserve beyond its frames, such as parts of its header.
+type lossReporter interface {
+	EncodeLosses() []string
+}
+
+// CanRoundtrip returns true if encoding the tag with its own version and
+// decoding it again would preserve everything, otherwise false and the
+// reasons why. Every frame must come back with the same ID, flags and data in
+// the same order, and the version must be able to keep what it decoded from
+// the headers. Padding isn't counted.
+func CanRoundtrip(tag Tag) (bool, []string) {
+	ver, ok := versionOf(tag)
+	if !ok {
+		return false, []string{"tag version is not registered"}
+	}
+
+	buf := &bytes.Buffer{}
+	if err := ver.encode(buf, tag); err != nil {
+		return false, []string{fmt.Sprintf("encode fails: %v", err)}
+	}
+
+	decoded, err := ver.decode(buf)
+	if err != nil {
+		return false, []string{fmt.Sprintf("decode of the encoded tag fails: %v", err)}
+	}
+
+	var reasons []string
+	if lr, ok := tag.(lossReporter); ok {
+		reasons = append(reasons, lr.EncodeLosses()...)
+	}
+	reasons = append(reasons, compareFrames(tag, decoded)...)
+
+	return len(reasons) == 0, reasons
+}
+
+// compareFrames describes how the frames of b differ from those of a.
+func compareFrames(a, b Tag) []string {
+	type frame struct {
+		id   string
+		data []byte
+	}
+
+	var before, after []frame
+	a.EachFrame(func(id string, data []byte) error {
+		before = append(before, frame{id, data})
+		return nil
+	})
+	b.EachFrame(func(id string, data []byte) error {
+		after = append(after, frame{id, data})
+		return nil
+	})
+
+	if len(before) != len(after) {
+		return []string{fmt.Sprintf("%d frames become %d", len(before), len(after))}
+	}
+
+	var reasons []string
+	for i := range before {
+		switch {
+		case before[i].id != after[i].id:
+			reasons = append(reasons, fmt.Sprintf("frame %s becomes %s", before[i].id, after[i].id))
+		case !bytes.Equal(before[i].data, after[i].data):
+			reasons = append(reasons, fmt.Sprintf("frame %s data is not preserved", before[i].id))
+		case a.FrameFlags(before[i].id) != b.FrameFlags(after[i].id):
+			reasons = append(reasons, fmt.Sprintf("frame %s flags are not preserved", before[i].id))
+		}
+	}
+	return reasons
+}
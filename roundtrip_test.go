@@ -0,0 +1,37 @@
+package id3v2_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/jlubawy/go-id3v2"
+)
+
+func TestCanRoundtrip(t *testing.T) {
+	tag, _, err := id3v2.Decode(bytes.NewReader(testTag))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, reasons := id3v2.CanRoundtrip(tag); !ok {
+		t.Errorf("expected the test tag to round-trip but got %v", reasons)
+	}
+
+	// Extended header with a CRC-32
+	b := []byte{
+		'I', 'D', '3', 3, 0, 0x40, 0, 0, 0, 29,
+		0, 0, 0, 10, 0x80, 0, 0, 0, 0, 0, 0x12, 0x34, 0x56, 0x78,
+		'T', 'I', 'T', '2', 0, 0, 0, 5, 0, 0,
+		0, 'T', 'e', 's', 't',
+	}
+	if tag, _, err = id3v2.Decode(bytes.NewReader(b)); err != nil {
+		t.Fatal(err)
+	}
+	if ok, reasons := id3v2.CanRoundtrip(tag); ok || len(reasons) != 1 {
+		t.Errorf("expected the CRC-32 not to round-trip but got %t, %v", ok, reasons)
+	}
+
+	tag.SetFrame("ABCD", []byte{1})
+	if ok, reasons := id3v2.CanRoundtrip(tag); ok || len(reasons) != 1 {
+		t.Errorf("expected an unsupported frame to fail encode but got %t, %v", ok, reasons)
+	}
+}
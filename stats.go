@@ -0,0 +1,71 @@
+package id3v2
+
+import (
+	"strings"
+)
+
+// Frames other than text information frames that start with a text encoding
+// byte.
+var encodedFrames = map[string]bool{
+	"APIC": true,
+	"COMM": true,
+	"COMR": true,
+	"GEOB": true,
+	"OWNE": true,
+	"SYLT": true,
+	"USER": true,
+	"USLT": true,
+	"WXXX": true,
+}
+
+// Stats accumulates the text encodings and picture MIME types used across
+// many tags, such as when surveying a library before migrating it. The zero
+// value is ready to use.
+type Stats struct {
+	encodings map[byte]int
+	mimeTypes map[string]int
+}
+
+// A StatsReport gives the number of frames using each text encoding and the
+// number of pictures of each MIME type.
+type StatsReport struct {
+	Encodings map[byte]int
+	MIMETypes map[string]int
+}
+
+// Add counts the encodings and MIME types used by a tag.
+func (s *Stats) Add(tag Tag) {
+	if s.encodings == nil {
+		s.encodings = make(map[byte]int)
+		s.mimeTypes = make(map[string]int)
+	}
+
+	tag.EachFrame(func(id string, data []byte) error {
+		if len(data) < 1 || !(strings.HasPrefix(id, "T") || encodedFrames[id]) {
+			return nil
+		}
+		s.encodings[data[0]]++
+
+		if id == "APIC" {
+			if pic, err := ParseAPIC(data); err == nil {
+				s.mimeTypes[pic.MIMEType]++
+			}
+		}
+		return nil
+	})
+}
+
+// Report returns the counts accumulated so far.
+func (s *Stats) Report() StatsReport {
+	r := StatsReport{
+		Encodings: make(map[byte]int),
+		MIMETypes: make(map[string]int),
+	}
+	for enc, n := range s.encodings {
+		r.Encodings[enc] = n
+	}
+	for mime, n := range s.mimeTypes {
+		r.MIMETypes[mime] = n
+	}
+	return r
+}
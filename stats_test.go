@@ -0,0 +1,31 @@
+package id3v2_test
+
+import (
+	"testing"
+
+	"github.com/jlubawy/go-id3v2"
+)
+
+func TestStats(t *testing.T) {
+	var s id3v2.Stats
+
+	for i := 0; i < 2; i++ {
+		tag, err := id3v2.NewTag("id3v2.3.0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		tag.SetFrame("TIT2", []byte("\x00Test"))
+		tag.SetFrame("TPE1", []byte("\x01\xFF\xFEA\x00"))
+		tag.SetFrame("APIC", []byte("\x00image/jpeg\x00\x03\x00\xFF\xD8"))
+		tag.SetFrame("PRIV", []byte("owner\x00"))
+		s.Add(tag)
+	}
+
+	r := s.Report()
+	if r.Encodings[0x00] != 4 || r.Encodings[0x01] != 2 || len(r.Encodings) != 2 {
+		t.Errorf("expected 4 ISO-8859-1 and 2 UTF-16 frames but got %v", r.Encodings)
+	}
+	if r.MIMETypes["image/jpeg"] != 2 || len(r.MIMETypes) != 1 {
+		t.Errorf("expected 2 image/jpeg pictures but got %v", r.MIMETypes)
+	}
+}
@@ -0,0 +1,42 @@
+package id3v2
+
+import (
+	"io"
+	"strings"
+)
+
+// A TextFrame is a decoded text information frame.
+type TextFrame struct {
+	ID   string
+	Text string
+}
+
+// StreamText decodes the tag in r and sends each of its text information
+// frames over the returned channel in decoded order, closing it after the
+// last one. The channel is buffered to hold every frame, so a consumer may
+// stop reading at any point. Other frames, and text frames that fail to
+// decode, are skipped.
+func StreamText(r io.Reader) (<-chan TextFrame, error) {
+	tag, _, err := Decode(r)
+	if err != nil {
+		return nil, err
+	}
+
+	c := make(chan TextFrame, len(tag.FrameOrder()))
+	tag.EachFrame(func(id string, data []byte) error {
+		if !strings.HasPrefix(id, "T") {
+			return nil
+		}
+
+		s, err := DecodeTextFrame(data)
+		if err != nil {
+			return nil
+		}
+
+		c <- TextFrame{ID: id, Text: s}
+		return nil
+	})
+	close(c)
+
+	return c, nil
+}
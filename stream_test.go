@@ -0,0 +1,50 @@
+package id3v2_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/jlubawy/go-id3v2"
+	"github.com/jlubawy/go-id3v2/id3v230"
+)
+
+func TestStreamText(t *testing.T) {
+	c, err := id3v2.StreamText(bytes.NewReader(testTag))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var frames []id3v2.TextFrame
+	for f := range c {
+		frames = append(frames, f)
+	}
+
+	if len(frames) != 1 || frames[0] != (id3v2.TextFrame{ID: "TIT2", Text: "Test"}) {
+		t.Errorf("expected a single TIT2 text frame but got %v", frames)
+	}
+}
+
+func TestStreamTextStopEarly(t *testing.T) {
+	tg := id3v230.NewTag()
+	tg.AddFrame("TIT2", id3v2.EncodeTextFrame("Title", id3v2.EncodingISO88591))
+	tg.AddFrame("TPE1", id3v2.EncodeTextFrame("Artist", id3v2.EncodingISO88591))
+
+	buf := &bytes.Buffer{}
+	if err := id3v230.Encode(buf, tg); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := id3v2.StreamText(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Every frame is already buffered, so reading one and stopping leaves
+	// nothing blocked
+	if f := <-c; f.ID != "TIT2" {
+		t.Errorf("expected TIT2 first but got %s", f.ID)
+	}
+	if n := len(c); n != 1 {
+		t.Errorf("expected 1 frame left buffered but got %d", n)
+	}
+}
@@ -0,0 +1,70 @@
+package id3v2
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+)
+
+// CopyWithoutTag copies src to dst leaving out the tag at its start, along
+// with its padding and the ID3v2.4 footer if there is one. Input without a
+// tag is copied unchanged.
+func CopyWithoutTag(dst io.Writer, src io.Reader) error {
+	var h [headerSize]byte
+
+	n, err := io.ReadFull(src, h[:])
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return err
+	}
+
+	if n == headerSize && isHeader(h[:]) {
+		skip := int64(tagSize(h[:])) - headerSize
+		if m, err := io.CopyN(ioutil.Discard, src, skip); err != nil {
+			if err == io.EOF {
+				return errTruncatedTag(skip+headerSize, m+headerSize)
+			}
+			return err
+		}
+	} else if _, err := dst.Write(h[:n]); err != nil {
+		return err
+	}
+
+	_, err = io.Copy(dst, src)
+	return err
+}
+
+// errTruncatedTag is returned when the input ends after n bytes of a tag
+// declared to be size bytes.
+func errTruncatedTag(size, n int64) error {
+	return fmt.Errorf("id3v2: expected a tag of %d bytes but the input ended after %d", size, n)
+}
+
+// StripTag removes the tag from the start of the file at path, leaving only
+// the audio. A file without a tag is left untouched, and like CopyWithoutTag
+// an error is returned if the tag runs past the end of the file.
+func StripTag(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	offset, err := AudioOffset(f)
+	if err != nil {
+		return err
+	}
+	if offset == 0 {
+		return nil
+	}
+
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if offset > fi.Size() {
+		return errTruncatedTag(offset, fi.Size())
+	}
+
+	return rewriteFile(f, path, nil, offset)
+}
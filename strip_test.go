@@ -0,0 +1,90 @@
+package id3v2_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jlubawy/go-id3v2"
+)
+
+func TestStripTag(t *testing.T) {
+	for _, tag := range [][]byte{testTag, nil} {
+		path := writeTestFile(t, tag)
+		defer os.RemoveAll(filepath.Dir(path))
+
+		if err := id3v2.StripTag(path); err != nil {
+			t.Fatal(err)
+		}
+
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(b, testAudio) {
+			t.Errorf("expected only the audio %v but got %v", testAudio, b)
+		}
+	}
+}
+
+func TestStripTagTruncated(t *testing.T) {
+	path := writeTestFile(t, nil)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	// The tag declares more bytes than the file has
+	b := append(append([]byte{}, testTag[:10]...), testAudio...)
+	b[9] = 0x7F
+	if err := ioutil.WriteFile(path, b, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	want := id3v2.CopyWithoutTag(ioutil.Discard, bytes.NewReader(b))
+	if err := id3v2.StripTag(path); err == nil || want == nil || err.Error() != want.Error() {
+		t.Errorf("expected error '%v' but got '%v'", want, err)
+	}
+
+	got, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, b) {
+		t.Errorf("expected the file to be left untouched but got %v", got)
+	}
+}
+
+func TestCopyWithoutTag(t *testing.T) {
+	footer := []byte{
+		'I', 'D', '3', 4, 0, 0x10, 0, 0, 0, 15,
+		'T', 'I', 'T', '2', 0, 0, 0, 5, 0, 0,
+		0, 'T', 'e', 's', 't',
+		'3', 'D', 'I', 4, 0, 0x10, 0, 0, 0, 15,
+	}
+
+	cases := []struct {
+		name    string
+		in, out []byte
+	}{
+		{"tag", append(append([]byte{}, testTag...), testAudio...), testAudio},
+		{"footer", append(append([]byte{}, footer...), testAudio...), testAudio},
+		{"no tag", testAudio, testAudio},
+		{"short", testAudio[:4], testAudio[:4]},
+	}
+
+	for _, c := range cases {
+		buf := &bytes.Buffer{}
+		if err := id3v2.CopyWithoutTag(buf, bytes.NewReader(c.in)); err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+
+		if !bytes.Equal(buf.Bytes(), c.out) {
+			t.Errorf("%s: expected %v but got %v", c.name, c.out, buf.Bytes())
+		}
+	}
+
+	if err := id3v2.CopyWithoutTag(ioutil.Discard, bytes.NewReader(testTag[:12])); err == nil {
+		t.Error("expected an error for a truncated tag")
+	}
+}
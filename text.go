@@ -0,0 +1,259 @@
+package id3v2
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"unicode/utf16"
+)
+
+var ErrFrameNotFound = errors.New("id3v2: frame not found")
+
+// Text encodings as bytes, for comparing with the first byte of frames.
+const (
+	encodingISO88591 = byte(EncodingISO88591)
+	encodingUTF16    = byte(EncodingUTF16)
+	encodingUTF16BE  = byte(EncodingUTF16BE)
+	encodingUTF8     = byte(EncodingUTF8)
+)
+
+// TextOptions control how DecodeTextWithOptions decodes text frames. The zero
+// value decodes strictly.
+type TextOptions struct {
+	// GuessMissingEncoding decodes the whole frame as ISO-8859-1 when the
+	// first byte is a printable ASCII character rather than a known text
+	// encoding, as written by tools that forget the encoding byte.
+	GuessMissingEncoding bool
+}
+
+// DecodeTextFrame decodes the data of a text information frame, which starts
+// with a text encoding byte, into a string with any null terminator removed.
+// UTF-16 is decoded in the byte order given by its BOM. Unknown text encodings
+// return an error.
+func DecodeTextFrame(data []byte) (string, error) {
+	return DecodeTextWithOptions(data, TextOptions{})
+}
+
+// DecodeTextWithOptions decodes the data of a text information frame into a
+// string with any null terminator removed.
+func DecodeTextWithOptions(data []byte, opts TextOptions) (string, error) {
+	if len(data) < 1 {
+		return "", fmt.Errorf("id3v2: text frame is missing its encoding byte")
+	}
+
+	if opts.GuessMissingEncoding && data[0] >= 0x20 && data[0] < 0x7F {
+		s, err := decodeString(encodingISO88591, data)
+		return trimNull(s), err
+	}
+
+	s, err := decodeString(data[0], data[1:])
+	if err != nil {
+		return "", err
+	}
+
+	return trimNull(s), nil
+}
+
+// decodeString decodes b into a string using the given text encoding.
+func decodeString(enc byte, b []byte) (string, error) {
+	switch enc {
+	case encodingISO88591:
+		r := make([]rune, len(b))
+		for i, c := range b {
+			r[i] = rune(c)
+		}
+		return string(r), nil
+
+	case encodingUTF16:
+		if len(b) < 2 {
+			if len(b) == 0 {
+				return "", nil
+			}
+			return "", fmt.Errorf("id3v2: UTF-16 string is missing its BOM")
+		}
+		switch {
+		case b[0] == 0xFF && b[1] == 0xFE:
+			return decodeUTF16(b[2:], false), nil
+		case b[0] == 0xFE && b[1] == 0xFF:
+			return decodeUTF16(b[2:], true), nil
+		}
+		return "", fmt.Errorf("id3v2: UTF-16 string is missing its BOM")
+
+	case encodingUTF16BE:
+		return decodeUTF16(b, true), nil
+
+	case encodingUTF8:
+		return string(b), nil
+	}
+
+	return "", fmt.Errorf("id3v2: unknown text encoding $%02X", enc)
+}
+
+func decodeUTF16(b []byte, bigEndian bool) string {
+	u := make([]uint16, len(b)/2)
+	for i := range u {
+		if bigEndian {
+			u[i] = uint16(b[2*i])<<8 | uint16(b[2*i+1])
+		} else {
+			u[i] = uint16(b[2*i+1])<<8 | uint16(b[2*i])
+		}
+	}
+	return string(utf16.Decode(u))
+}
+
+// splitString splits b after the first null terminated string, the
+// terminator being two bytes wide for the UTF-16 encodings.
+func splitString(enc byte, b []byte) (str, rest []byte, ok bool) {
+	if enc == encodingUTF16 || enc == encodingUTF16BE {
+		for i := 0; i+1 < len(b); i += 2 {
+			if b[i] == 0 && b[i+1] == 0 {
+				return b[:i], b[i+2:], true
+			}
+		}
+		return b, nil, false
+	}
+
+	i := bytes.IndexByte(b, 0)
+	if i < 0 {
+		return b, nil, false
+	}
+	return b[:i], b[i+1:], true
+}
+
+func errShortFrame(id string) error {
+	return fmt.Errorf("id3v2: %s frame is too short", id)
+}
+
+func trimNull(s string) string {
+	for len(s) > 0 && s[len(s)-1] == 0 {
+		s = s[:len(s)-1]
+	}
+	return s
+}
+
+// ParseTSIZ parses the data of a TSIZ frame, the size of the audio data in
+// bytes excluding the ID3v2 tag.
+func ParseTSIZ(data []byte) (uint64, error) {
+	s, err := DecodeTextFrame(data)
+	if err != nil {
+		return 0, err
+	}
+
+	size, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("id3v2: invalid TSIZ '%s'", s)
+	}
+
+	return size, nil
+}
+
+// CheckTSIZ compares the audio size declared by the tag's TSIZ frame with the
+// number of bytes remaining in audio, which should be positioned just after
+// the tag. A mismatch means the file has been truncated or padded.
+func CheckTSIZ(tag Tag, audio io.Reader) (declared, actual uint64, err error) {
+	data, ok := tag.Frames()["TSIZ"]
+	if !ok {
+		return 0, 0, ErrFrameNotFound
+	}
+
+	declared, err = ParseTSIZ(data)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	n, err := io.Copy(ioutil.Discard, audio)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return declared, uint64(n), nil
+}
+
+// ParseTDAT parses the data of a TDAT frame, a date in the DDMM format.
+func ParseTDAT(data []byte) (day, month int, err error) {
+	day, month, err = parseFourDigits("TDAT", data)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if day < 1 || day > 31 {
+		return 0, 0, fmt.Errorf("id3v2: TDAT day %d out of range 1-31", day)
+	}
+	if month < 1 || month > 12 {
+		return 0, 0, fmt.Errorf("id3v2: TDAT month %d out of range 1-12", month)
+	}
+
+	return day, month, nil
+}
+
+// ParseTIME parses the data of a TIME frame, a time in the HHMM format.
+func ParseTIME(data []byte) (hour, minute int, err error) {
+	hour, minute, err = parseFourDigits("TIME", data)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if hour > 23 {
+		return 0, 0, fmt.Errorf("id3v2: TIME hour %d out of range 0-23", hour)
+	}
+	if minute > 59 {
+		return 0, 0, fmt.Errorf("id3v2: TIME minute %d out of range 0-59", minute)
+	}
+
+	return hour, minute, nil
+}
+
+// parseFourDigits parses a text frame which is always four numeric
+// characters, returning the first and last pair as numbers.
+func parseFourDigits(id string, data []byte) (int, int, error) {
+	s, err := DecodeTextFrame(data)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if len(s) != 4 {
+		return 0, 0, fmt.Errorf("id3v2: expected %s to be 4 characters but got '%s'", id, s)
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return 0, 0, fmt.Errorf("id3v2: expected %s to be numeric but got '%s'", id, s)
+		}
+	}
+
+	return int(s[0]-'0')*10 + int(s[1]-'0'), int(s[2]-'0')*10 + int(s[3]-'0'), nil
+}
+
+// ParseTMCL parses the data of an ID3v2.4 TMCL musician credits list frame
+// into instrument and name pairs.
+func ParseTMCL(data []byte) ([][2]string, error) {
+	if len(data) < 1 {
+		return nil, errShortFrame("TMCL")
+	}
+
+	enc := data[0]
+
+	var strs []string
+	for b := data[1:]; len(b) > 0; {
+		var str []byte
+		str, b, _ = splitString(enc, b)
+
+		s, err := decodeString(enc, str)
+		if err != nil {
+			return nil, err
+		}
+		strs = append(strs, s)
+	}
+
+	if len(strs)%2 != 0 {
+		return nil, fmt.Errorf("id3v2: expected TMCL to contain instrument and name pairs but got %d strings", len(strs))
+	}
+
+	pairs := make([][2]string, len(strs)/2)
+	for i := range pairs {
+		pairs[i] = [2]string{strs[2*i], strs[2*i+1]}
+	}
+	return pairs, nil
+}
@@ -0,0 +1,104 @@
+package id3v2
+
+import (
+	"testing"
+)
+
+func TestParseTSIZ(t *testing.T) {
+	size, err := ParseTSIZ([]byte("\x001234567\x00"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size != 1234567 {
+		t.Errorf("expected size 1234567 but got %d", size)
+	}
+
+	if _, err := ParseTSIZ([]byte("\x00big")); err == nil {
+		t.Error("expected an error for a non-numeric TSIZ")
+	}
+}
+
+func TestParseTDATAndTIME(t *testing.T) {
+	if day, month, err := ParseTDAT([]byte("\x002512")); err != nil || day != 25 || month != 12 {
+		t.Errorf("expected 25/12 but got %d/%d, %v", day, month, err)
+	}
+	if hour, minute, err := ParseTIME([]byte("\x002359")); err != nil || hour != 23 || minute != 59 {
+		t.Errorf("expected 23:59 but got %d:%d, %v", hour, minute, err)
+	}
+
+	for _, data := range []string{"\x003212", "\x000013", "\x00251", "\x0025-2"} {
+		if _, _, err := ParseTDAT([]byte(data)); err == nil {
+			t.Errorf("expected an error for TDAT %q", data)
+		}
+	}
+	for _, data := range []string{"\x002400", "\x001260"} {
+		if _, _, err := ParseTIME([]byte(data)); err == nil {
+			t.Errorf("expected an error for TIME %q", data)
+		}
+	}
+}
+
+func TestDecodeTextGuessMissingEncoding(t *testing.T) {
+	data := []byte("Test")
+
+	if _, err := DecodeTextWithOptions(data, TextOptions{}); err == nil {
+		t.Error("expected an error for a missing encoding byte")
+	}
+
+	s, err := DecodeTextWithOptions(data, TextOptions{GuessMissingEncoding: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s != "Test" {
+		t.Errorf("expected %q but got %q", "Test", s)
+	}
+}
+
+func TestParseTMCL(t *testing.T) {
+	pairs, err := ParseTMCL([]byte("\x03guitar\x00Jimmy Page\x00drums\x00John Bonham\x00"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := [][2]string{{"guitar", "Jimmy Page"}, {"drums", "John Bonham"}}
+	if len(pairs) != len(expected) {
+		t.Fatalf("expected %v but got %v", expected, pairs)
+	}
+	for i := range expected {
+		if pairs[i] != expected[i] {
+			t.Errorf("expected %v but got %v", expected[i], pairs[i])
+		}
+	}
+
+	if _, err := ParseTMCL([]byte("\x03guitar\x00")); err == nil {
+		t.Error("expected an error for an unpaired instrument")
+	}
+}
+
+func TestDecodeTextFrame(t *testing.T) {
+	tests := []struct {
+		data     []byte
+		expected string
+	}{
+		{[]byte{0x00, 'C', 'a', 'f', 0xE9, 0x00}, "Café"},
+		{[]byte{0x01, 0xFF, 0xFE, 'C', 0, 'a', 0, 'f', 0, 0xE9, 0, 0, 0}, "Café"},
+		{[]byte{0x01, 0xFE, 0xFF, 0, 'C', 0, 'a', 0, 'f', 0, 0xE9}, "Café"},
+		{[]byte{0x02, 0, 'C', 0, 'a', 0, 'f', 0, 0xE9, 0, 0}, "Café"},
+		{[]byte{0x03, 'C', 'a', 'f', 0xC3, 0xA9}, "Café"},
+	}
+
+	for _, test := range tests {
+		s, err := DecodeTextFrame(test.data)
+		if err != nil {
+			t.Errorf("%v: %v", test.data, err)
+			continue
+		}
+		if s != test.expected {
+			t.Errorf("%v: expected '%s' but got '%s'", test.data, test.expected, s)
+		}
+	}
+
+	if _, err := DecodeTextFrame([]byte{0x04, 'a'}); err == nil {
+		t.Error("expected an error for an unknown text encoding")
+	}
+}
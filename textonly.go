@@ -0,0 +1,34 @@
+package id3v2
+
+import (
+	"strings"
+)
+
+// TextOnly returns a new tag of the same version holding only the text, URL,
+// comment and lyrics frames of tag, dropping binary frames such as APIC, GEOB
+// and PRIV. The frames are copied with their flags and extra header. It
+// returns nil if the tag's version isn't registered.
+func TextOnly(tag Tag) Tag {
+	ver, ok := versionOf(tag)
+	if !ok {
+		return nil
+	}
+
+	text := ver.newTag()
+	for i, id := range tag.FrameOrder() {
+		if isTextOnlyFrame(id) {
+			text.AppendFrame(tag.FrameAt(i))
+		}
+	}
+	return text
+}
+
+// isTextOnlyFrame returns true if the frame ID is kept by TextOnly, including
+// the three character IDs of ID3v2.2.0.
+func isTextOnlyFrame(id string) bool {
+	switch id {
+	case "COMM", "USLT", "COM", "ULT":
+		return true
+	}
+	return strings.HasPrefix(id, "T") || strings.HasPrefix(id, "W")
+}
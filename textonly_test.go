@@ -0,0 +1,72 @@
+package id3v2_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/jlubawy/go-id3v2"
+)
+
+func TestTextOnly(t *testing.T) {
+	tag := buildTag(t,
+		"TIT2", []byte("\x00Test"),
+		"APIC", []byte("\x00image/png\x00\x03\x00\x89PNG"),
+		"COMM", []byte("\x00eng\x00One"),
+		"PRIV", []byte("owner\x00data"),
+		"COMM", []byte("\x00eng\x00Two"),
+		"WOAR", []byte("http://example.com"),
+	)
+
+	text := id3v2.TextOnly(tag)
+	if text == nil {
+		t.Fatal("expected a tag but got nil")
+	}
+
+	expected := []string{"TIT2", "COMM", "COMM", "WOAR"}
+	order := text.FrameOrder()
+	if len(order) != len(expected) {
+		t.Fatalf("expected frames %v but got %v", expected, order)
+	}
+	for i := range expected {
+		if order[i] != expected[i] {
+			t.Fatalf("expected frames %v but got %v", expected, order)
+		}
+	}
+
+	if n := len(tag.FrameOrder()); n != 6 {
+		t.Errorf("expected the original tag to keep 6 frames but got %d", n)
+	}
+	if text.Size() >= tag.Size() {
+		t.Errorf("expected size less than %d but got %d", tag.Size(), text.Size())
+	}
+}
+
+func TestTextOnlyKeepsFrameState(t *testing.T) {
+	b := []byte{
+		'I', 'D', '3', 3, 0, 0, 0, 0, 0, 47,
+		'T', 'Z', 'Z', 'Z', 0, 0, 0, 4, 0, 0,
+		0, 'a', 'b', 'c',
+		'P', 'R', 'I', 'V', 0, 0, 0, 4, 0, 0,
+		'o', 0, 1, 2,
+		'T', 'I', 'T', '2', 0, 0, 0, 9, 0, 0x80,
+		0, 0, 0, 5, 0x78, 0x9C, 0x01, 0x02, 0x03,
+	}
+
+	tag, _, err := id3v2.Decode(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The unsupported TZZZ frame is still passed through and the compressed
+	// TIT2 keeps its decompressed size
+	expected := append([]byte{'I', 'D', '3', 3, 0, 0, 0, 0, 0, 33}, b[10:24]...)
+	expected = append(expected, b[38:]...)
+
+	buf := &bytes.Buffer{}
+	if err := id3v2.EncodeAs(buf, id3v2.TextOnly(tag), "id3v2.3.0"); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), expected) {
+		t.Errorf("expected %v but got %v", expected, buf.Bytes())
+	}
+}
@@ -0,0 +1,43 @@
+package id3v2
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// ParseUFID parses the data of a UFID frame into the owner identifier, a URL
+// such as "http://musicbrainz.org", and the identifier itself. A tag may hold
+// a UFID frame for each owner, see Tag.FramesByID. Identifiers longer than the
+// specification allows are returned as they are, Validate reports them.
+//
+// Owner identifier   <text string> $00
+// Identifier         <up to 64 bytes binary data>
+func ParseUFID(data []byte) (owner string, id []byte, err error) {
+	i := bytes.IndexByte(data, 0)
+	if i < 0 {
+		return "", nil, errShortFrame("UFID")
+	}
+
+	if owner, err = decodeString(encodingISO88591, data[:i]); err != nil {
+		return "", nil, err
+	}
+	return owner, data[i+1:], nil
+}
+
+// EncodeUFID encodes an owner identifier and identifier as the data of a UFID
+// frame. The owner must be a non-empty ISO-8859-1 string and the identifier
+// at most MaxUFIDIdentifierSize bytes.
+func EncodeUFID(owner string, id []byte) ([]byte, error) {
+	if owner == "" {
+		return nil, fmt.Errorf("id3v2: UFID owner identifier must not be empty")
+	}
+	if !isISO88591(owner) {
+		return nil, fmt.Errorf("id3v2: UFID owner identifier must be ISO-8859-1 but got '%s'", owner)
+	}
+	if err := checkUFIDIdentifier(id); err != nil {
+		return nil, err
+	}
+
+	b := append(encodeString(EncodingISO88591, owner), 0)
+	return append(b, id...), nil
+}
@@ -0,0 +1,54 @@
+package id3v2
+
+import (
+	"bufio"
+	"io"
+)
+
+// unsyncReader reverses the unsynchronisation scheme by dropping the $00 that
+// an encoder inserted after every $FF.
+type unsyncReader struct {
+	r      io.ByteReader
+	prevFF bool
+}
+
+// NewUnsyncReader returns a reader that reverses unsynchronisation on the
+// bytes read from r. Since it may read ahead, r should be limited to the
+// unsynchronised part of the tag.
+func NewUnsyncReader(r io.Reader) io.Reader {
+	return &unsyncReader{r: bufio.NewReader(r)}
+}
+
+func (u *unsyncReader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		b, err := u.r.ReadByte()
+		if err != nil {
+			return n, err
+		}
+
+		if u.prevFF && b == 0x00 {
+			u.prevFF = false
+			continue
+		}
+		u.prevFF = b == 0xFF
+
+		p[n] = b
+		n++
+	}
+	return n, nil
+}
+
+// Unsynchronise applies the unsynchronisation scheme to b, inserting a $00
+// after every $FF that's followed by a byte of %111xxxxx or $00, or that ends
+// b, so that no false synchronisation can be found.
+func Unsynchronise(b []byte) []byte {
+	out := make([]byte, 0, len(b))
+	for i, c := range b {
+		out = append(out, c)
+		if c == 0xFF && (i+1 == len(b) || b[i+1] >= 0xE0 || b[i+1] == 0x00) {
+			out = append(out, 0x00)
+		}
+	}
+	return out
+}
@@ -0,0 +1,72 @@
+package id3v2
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// ParseURLFrame parses the data of a URL link frame other than WXXX, which is
+// a single ISO-8859-1 URL without an encoding byte. Anything after a null is
+// ignored.
+func ParseURLFrame(data []byte) (string, error) {
+	if i := bytes.IndexByte(data, 0); i >= 0 {
+		data = data[:i]
+	}
+	if len(data) == 0 {
+		return "", fmt.Errorf("id3v2: URL frame is empty")
+	}
+
+	return decodeString(encodingISO88591, data)
+}
+
+// urlFrame looks up and parses the first URL link frame the tag has out of
+// ids, like the ID3v2.3.0 and ID3v2.2.0 IDs of the same frame. It returns
+// ErrFrameNotFound if the tag has none of them.
+func urlFrame(tag Tag, ids ...string) (string, error) {
+	for _, id := range ids {
+		if data, ok := tag.GetFrame(id); ok {
+			return ParseURLFrame(data)
+		}
+	}
+	return "", ErrFrameNotFound
+}
+
+// CommercialURL returns the first commercial information URL (WCOM).
+func CommercialURL(tag Tag) (string, error) {
+	return urlFrame(tag, "WCOM", "WCM")
+}
+
+// CopyrightURL returns the copyright/legal information URL (WCOP).
+func CopyrightURL(tag Tag) (string, error) {
+	return urlFrame(tag, "WCOP", "WCP")
+}
+
+// AudioFileURL returns the official audio file webpage (WOAF).
+func AudioFileURL(tag Tag) (string, error) {
+	return urlFrame(tag, "WOAF", "WAF")
+}
+
+// ArtistURL returns the first official artist/performer webpage (WOAR).
+func ArtistURL(tag Tag) (string, error) {
+	return urlFrame(tag, "WOAR", "WAR")
+}
+
+// AudioSourceURL returns the official audio source webpage (WOAS).
+func AudioSourceURL(tag Tag) (string, error) {
+	return urlFrame(tag, "WOAS", "WAS")
+}
+
+// RadioStationURL returns the official internet radio station homepage (WORS).
+func RadioStationURL(tag Tag) (string, error) {
+	return urlFrame(tag, "WORS")
+}
+
+// PaymentURL returns the payment URL (WPAY).
+func PaymentURL(tag Tag) (string, error) {
+	return urlFrame(tag, "WPAY")
+}
+
+// PublisherURL returns the publisher's official webpage (WPUB).
+func PublisherURL(tag Tag) (string, error) {
+	return urlFrame(tag, "WPUB", "WPB")
+}
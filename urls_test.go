@@ -0,0 +1,39 @@
+package id3v2_test
+
+import (
+	"testing"
+
+	"github.com/jlubawy/go-id3v2"
+)
+
+func TestURLAccessors(t *testing.T) {
+	tag := buildTag(t,
+		"WOAR", []byte("http://example.com/artist"),
+		"WOAR", []byte("http://example.com/other"),
+		"WPAY", []byte("http://example.com/pay\x00"),
+		"WOAF", []byte("http://example.com/caf\xE9"),
+	)
+
+	tests := []struct {
+		fn       func(id3v2.Tag) (string, error)
+		expected string
+	}{
+		{id3v2.ArtistURL, "http://example.com/artist"},
+		{id3v2.PaymentURL, "http://example.com/pay"},
+		{id3v2.AudioFileURL, "http://example.com/café"},
+	}
+
+	for _, test := range tests {
+		if s, err := test.fn(tag); err != nil || s != test.expected {
+			t.Errorf("expected '%s' but got '%s' (%v)", test.expected, s, err)
+		}
+	}
+
+	if _, err := id3v2.PublisherURL(tag); err != id3v2.ErrFrameNotFound {
+		t.Errorf("expected ErrFrameNotFound but got %v", err)
+	}
+
+	if _, err := id3v2.ParseURLFrame([]byte{0}); err == nil {
+		t.Error("expected an error for an empty URL")
+	}
+}
@@ -0,0 +1,87 @@
+package id3v2
+
+import (
+	"fmt"
+)
+
+// ParseTXXX splits a user defined text information frame into its description
+// and value.
+//
+// Text encoding    $xx
+// Description      <text string according to encoding> $00 (00)
+// Value            <text string according to encoding>
+func ParseTXXX(data []byte) (desc, value string, err error) {
+	if len(data) < 1 {
+		return "", "", errShortFrame("TXXX")
+	}
+
+	enc := data[0]
+	d, v, _ := splitString(enc, data[1:])
+
+	if desc, err = decodeString(enc, d); err != nil {
+		return "", "", err
+	}
+	if value, err = decodeString(enc, v); err != nil {
+		return "", "", err
+	}
+
+	return desc, trimNull(value), nil
+}
+
+// EncodeTXXX encodes a description and value as the data of a TXXX frame. An
+// encoding of EncodingAuto picks one able to represent both.
+func EncodeTXXX(desc, value string, enc Encoding) ([]byte, error) {
+	enc = resolveEncoding(enc, desc, value)
+	if err := checkEncoding("TXXX", enc); err != nil {
+		return nil, err
+	}
+
+	b := []byte{byte(enc)}
+	b = append(b, encodeString(enc, desc)...)
+	b = append(b, terminator(enc)...)
+	b = append(b, encodeString(enc, value)...)
+	return b, nil
+}
+
+// ParseWXXX splits a user defined URL link frame into its description and URL.
+// Only the description uses the text encoding, the URL is always ISO-8859-1.
+//
+// Text encoding    $xx
+// Description      <text string according to encoding> $00 (00)
+// URL              <text string>
+func ParseWXXX(data []byte) (desc, url string, err error) {
+	if len(data) < 1 {
+		return "", "", errShortFrame("WXXX")
+	}
+
+	enc := data[0]
+	d, u, _ := splitString(enc, data[1:])
+
+	if desc, err = decodeString(enc, d); err != nil {
+		return "", "", err
+	}
+	if url, err = decodeString(encodingISO88591, u); err != nil {
+		return "", "", err
+	}
+
+	return desc, trimNull(url), nil
+}
+
+// EncodeWXXX encodes a description and URL as the data of a WXXX frame. The
+// encoding only applies to the description, an encoding of EncodingAuto picks
+// one able to represent it.
+func EncodeWXXX(desc, url string, enc Encoding) ([]byte, error) {
+	enc = resolveEncoding(enc, desc)
+	if err := checkEncoding("WXXX", enc); err != nil {
+		return nil, err
+	}
+	if !isISO88591(url) {
+		return nil, fmt.Errorf("id3v2: WXXX URL must be ISO-8859-1 but got '%s'", url)
+	}
+
+	b := []byte{byte(enc)}
+	b = append(b, encodeString(enc, desc)...)
+	b = append(b, terminator(enc)...)
+	b = append(b, encodeString(EncodingISO88591, url)...)
+	return b, nil
+}
@@ -0,0 +1,129 @@
+package id3v2
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Picture types that may only appear once per tag.
+const (
+	PictureTypeFileIcon      = byte(0x01) // 32x32 pixels 'file icon' (PNG only)
+	PictureTypeOtherFileIcon = byte(0x02) // Other file icon
+)
+
+// Validate checks a tag against the rules of the ID3v2 specification that
+// aren't enforced while decoding. It returns one error for each violation
+// found, or nil if the tag conforms.
+func Validate(tag Tag) []error {
+	var errs []error
+
+	errs = append(errs, validateFrameCounts(tag)...)
+	errs = append(errs, validateAPIC(tag)...)
+	errs = append(errs, validateFieldLengths(tag)...)
+
+	return errs
+}
+
+// Frames other than text and URL frames that may only appear once per tag.
+var singletonFrames = map[string]bool{
+	"EQUA": true,
+	"ETCO": true,
+	"IPLS": true,
+	"MCDI": true,
+	"MLLT": true,
+	"OWNE": true,
+	"PCNT": true,
+	"POSS": true,
+	"RBUF": true,
+	"RVAD": true,
+	"RVRB": true,
+	"SYTC": true,
+}
+
+// isSingletonFrame returns true if there may only be one frame with the given
+// ID in a tag.
+func isSingletonFrame(id string) bool {
+	switch {
+	case id == "TXXX" || id == "WXXX" || id == "TXX" || id == "WXX":
+		return false
+	case id == "WCOM" || id == "WOAR" || id == "WCM" || id == "WAR":
+		// May repeat as long as the URLs differ
+		return false
+	case strings.HasPrefix(id, "T") || strings.HasPrefix(id, "W"):
+		return true
+	}
+	return singletonFrames[id]
+}
+
+// validateFrameCounts reports frames that appear more than once although the
+// specification allows only one of their kind.
+func validateFrameCounts(tag Tag) []error {
+	var errs []error
+
+	counts := make(map[string]int)
+	var ids []string
+	for _, id := range tag.FrameOrder() {
+		if counts[id] == 0 {
+			ids = append(ids, id)
+		}
+		counts[id]++
+	}
+
+	for _, id := range ids {
+		if counts[id] > 1 && isSingletonFrame(id) {
+			errs = append(errs, fmt.Errorf("id3v2: expected at most one %s frame but got %d", id, counts[id]))
+		}
+	}
+
+	return errs
+}
+
+// There may be several pictures attached to one file, each in their
+// individual "APIC" frame, but only one with the same content descriptor.
+// There may only be one picture with the picture type declared as picture
+// type $01 and $02 respectively. The same goes for the "PIC" frames of
+// ID3v2.2.0.
+func validateAPIC(tag Tag) []error {
+	var errs []error
+
+	for _, id := range []string{"APIC", "PIC"} {
+		counts := make(map[byte]int)
+		for _, data := range tag.FramesByID(id) {
+			pt, err := pictureType(id, data)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			counts[pt]++
+		}
+
+		for _, pt := range []byte{PictureTypeFileIcon, PictureTypeOtherFileIcon} {
+			if counts[pt] > 1 {
+				errs = append(errs, fmt.Errorf("id3v2: expected at most one %s frame with picture type $%02X but got %d", id, pt, counts[pt]))
+			}
+		}
+	}
+
+	return errs
+}
+
+// pictureType returns the picture type of an APIC frame, or of a PIC frame
+// whose image format is 3 characters in place of the MIME type.
+//
+// Text encoding   $xx
+// Image format    $xx xx xx
+// Picture type    $xx
+func pictureType(id string, data []byte) (byte, error) {
+	if id == "PIC" {
+		if len(data) < 5 {
+			return 0, errShortFrame("PIC")
+		}
+		return data[4], nil
+	}
+
+	pic, err := ParseAPIC(data)
+	if err != nil {
+		return 0, err
+	}
+	return pic.PictureType, nil
+}
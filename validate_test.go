@@ -0,0 +1,116 @@
+package id3v2_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/jlubawy/go-id3v2"
+	_ "github.com/jlubawy/go-id3v2/id3v220"
+)
+
+func TestValidateFrameCounts(t *testing.T) {
+	b := []byte{
+		'I', 'D', '3', 3, 0, 0, 0, 0, 0, 30,
+		'T', 'I', 'T', '2', 0, 0, 0, 5, 0, 0,
+		0, 'T', 'e', 's', 't',
+		'T', 'I', 'T', '2', 0, 0, 0, 5, 0, 0,
+		0, 'T', 'e', 's', 't',
+	}
+
+	tag, _, err := id3v2.Decode(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if errs := id3v2.Validate(tag); len(errs) != 1 {
+		t.Errorf("expected 1 error for duplicate TIT2 frames but got %v", errs)
+	}
+
+	tag, _, err = id3v2.Decode(bytes.NewReader(testTag))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if errs := id3v2.Validate(tag); len(errs) != 0 {
+		t.Errorf("expected no errors but got %v", errs)
+	}
+}
+
+func TestValidateFrameCountsV220(t *testing.T) {
+	tag, err := id3v2.NewTag("id3v2.2.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// User defined, commercial information and artist URL frames may repeat
+	for _, id := range []string{"TXX", "WXX", "WCM", "WAR"} {
+		tag.AddFrame(id, []byte("\x00a\x00b"))
+		tag.AddFrame(id, []byte("\x00c\x00d"))
+	}
+	tag.AddFrame("TT2", []byte("\x00Test"))
+	tag.AddFrame("TT2", []byte("\x00Test"))
+
+	errs := id3v2.Validate(tag)
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error for duplicate TT2 frames but got %v", errs)
+	}
+	if want := "id3v2: expected at most one TT2 frame but got 2"; errs[0].Error() != want {
+		t.Errorf("expected error '%s' but got '%s'", want, errs[0])
+	}
+}
+
+func TestValidateAPICFileIcons(t *testing.T) {
+	tag, err := id3v2.NewTag("id3v2.3.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, pic := range []id3v2.APIC{
+		{MIMEType: "image/png", PictureType: id3v2.PictureTypeFileIcon, Description: "a", Data: []byte{1}},
+		{MIMEType: "image/png", PictureType: id3v2.PictureTypeFileIcon, Description: "b", Data: []byte{2}},
+		{MIMEType: "image/png", PictureType: id3v2.PictureTypeOtherFileIcon, Description: "c", Data: []byte{3}},
+		{MIMEType: "image/png", PictureType: id3v2.PictureTypeOtherFileIcon, Description: "d", Data: []byte{4}},
+		{MIMEType: "image/jpeg", PictureType: 3, Description: "e", Data: []byte{5}},
+		{MIMEType: "image/jpeg", PictureType: 3, Description: "f", Data: []byte{6}},
+	} {
+		data, err := id3v2.EncodeAPIC(pic)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tag.AddFrame("APIC", data)
+	}
+
+	// Only the file icons are limited to one each, front covers may repeat
+	errs := id3v2.Validate(tag)
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors for the repeated file icons but got %v", errs)
+	}
+	for i, want := range []string{
+		"id3v2: expected at most one APIC frame with picture type $01 but got 2",
+		"id3v2: expected at most one APIC frame with picture type $02 but got 2",
+	} {
+		if errs[i].Error() != want {
+			t.Errorf("expected error '%s' but got '%s'", want, errs[i])
+		}
+	}
+}
+
+func TestValidatePICFileIcons(t *testing.T) {
+	tag, err := id3v2.NewTag("id3v2.2.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tag.AddFrame("PIC", []byte("\x00PNG\x01a\x00\x01"))
+	tag.AddFrame("PIC", []byte("\x00PNG\x01b\x00\x02"))
+	tag.AddFrame("PIC", []byte("\x00JPG\x03c\x00\x03"))
+	tag.AddFrame("PIC", []byte("\x00JPG\x03d\x00\x04"))
+
+	errs := id3v2.Validate(tag)
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error for the repeated file icon but got %v", errs)
+	}
+	if want := "id3v2: expected at most one PIC frame with picture type $01 but got 2"; errs[0].Error() != want {
+		t.Errorf("expected error '%s' but got '%s'", want, errs[0])
+	}
+}